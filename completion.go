@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+)
+
+const (
+	completeIndexes = "indexes"
+	completeTasks   = "tasks"
+	completeKeys    = "keys"
+)
+
+// ttl of the cached completions, tasks change all the time
+var completionTTL = map[string]time.Duration{
+	completeIndexes: time.Minute,
+	completeTasks:   5 * time.Second,
+	completeKeys:    time.Minute,
+}
+
+const (
+	completionIndexesLimit = 1000
+	completionTasksLimit   = 100
+	completionKeysLimit    = 1000
+)
+
+// completionCache caches the values completed in the shell, so a tab does
+// not call Meilisearch each time.
+type completionCache struct {
+	mu      sync.Mutex
+	entries map[string]completionEntry
+
+	// pending maps the uid of the tasks not finished yet to the kinds they
+	// change, which are not cached until the tasks are finished.
+	pending  map[int64][]string
+	finished func(uid int64) bool
+}
+
+type completionEntry struct {
+	values  []string
+	expires time.Time
+}
+
+func (c *completionCache) get(kind string, load func() ([]string, error)) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[kind]; ok && time.Now().Before(e.expires) {
+		return e.values
+	}
+
+	// checked before loading, so values loaded after the task is finished are cached
+	pending := c.isPending(kind)
+
+	values, err := load()
+	if err != nil {
+		return nil
+	}
+
+	if !pending {
+		c.entries[kind] = completionEntry{values: values, expires: time.Now().Add(completionTTL[kind])}
+	}
+	return values
+}
+
+// invalidate drops the cached values of kinds, or all values and pending tasks
+// when kinds is empty.
+func (c *completionCache) invalidate(kinds ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(kinds) == 0 {
+		c.entries = make(map[string]completionEntry)
+		c.pending = nil
+		return
+	}
+
+	for _, kind := range kinds {
+		delete(c.entries, kind)
+	}
+}
+
+// invalidateTask drops the cached values of kinds and keeps them out of the
+// cache until the task uid is finished, as they change when it is processed
+// rather than when it is enqueued.
+func (c *completionCache) invalidateTask(uid int64, kinds ...string) {
+	if len(kinds) == 0 {
+		return
+	}
+
+	c.invalidate(kinds...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pending == nil {
+		c.pending = make(map[int64][]string)
+	}
+	c.pending[uid] = kinds
+}
+
+// isPending reports whether a task changing kind is not finished yet,
+// forgetting the tasks which are. c.mu must be held.
+func (c *completionCache) isPending(kind string) bool {
+	pending := false
+	for uid, kinds := range c.pending {
+		if !slices.Contains(kinds, kind) {
+			continue
+		}
+
+		if c.finished == nil || c.finished(uid) {
+			delete(c.pending, uid)
+			continue
+		}
+		pending = true
+	}
+
+	return pending
+}
+
+func (s *Session) loadIndexUIDs() ([]string, error) {
+	res, err := s.Client().GetIndexes(&meilisearch.IndexesQuery{Limit: completionIndexesLimit})
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make([]string, 0, len(res.Results))
+	for _, idx := range res.Results {
+		uids = append(uids, idx.UID)
+	}
+
+	return uids, nil
+}
+
+func (s *Session) loadTaskUIDs() ([]string, error) {
+	res, err := s.Client().GetTasks(&meilisearch.TasksQuery{Limit: completionTasksLimit})
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make([]string, 0, len(res.Results))
+	for _, t := range res.Results {
+		uids = append(uids, strings.TrimSpace(fmt.Sprintf("%d\t%s %s %s", t.UID, t.Status, t.Type, t.IndexUID)))
+	}
+
+	return uids, nil
+}
+
+func (s *Session) loadKeyUIDs() ([]string, error) {
+	res, err := s.Client().GetKeys(&meilisearch.KeysQuery{Limit: completionKeysLimit})
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make([]string, 0, len(res.Results))
+	for _, k := range res.Results {
+		uids = append(uids, fmt.Sprintf("%s\t%s", k.UID, k.Name))
+	}
+
+	return uids, nil
+}
+
+// completeIndexUID completes the index uid given as first argument.
+func (s *Session) completeIndexUID(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	return s.completions.get(completeIndexes, s.loadIndexUIDs), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeIndexUIDs completes index uids given as flag value, e.g. --indexes.
+func (s *Session) completeIndexUIDs(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return s.completions.get(completeIndexes, s.loadIndexUIDs), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTaskUIDs completes task uids which are not given yet.
+func (s *Session) completeTaskUIDs(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	uids := make([]string, 0)
+	for _, v := range s.completions.get(completeTasks, s.loadTaskUIDs) {
+		uid, _, _ := strings.Cut(v, "\t")
+		if !slices.Contains(args, uid) {
+			uids = append(uids, v)
+		}
+	}
+
+	return uids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTaskUID completes the task uid given as first argument.
+func (s *Session) completeTaskUID(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return s.completeTaskUIDs(cmd, args, toComplete)
+}
+
+// completeKeyUID completes the key uid given as first argument.
+func (s *Session) completeKeyUID(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return s.completions.get(completeKeys, s.loadKeyUIDs), cobra.ShellCompDirectiveNoFileComp
+}
+
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// setValidArgs sets fn on the runnable cmds and sub-commands without a completion.
+func setValidArgs(fn completionFunc, cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		if cmd.Runnable() && cmd.ValidArgsFunction == nil {
+			cmd.ValidArgsFunction = fn
+		}
+		setValidArgs(fn, cmd.Commands()...)
+	}
+}
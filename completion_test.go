@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionCache(t *testing.T) {
+	c := &completionCache{entries: make(map[string]completionEntry)}
+
+	calls := 0
+	load := func() ([]string, error) {
+		calls++
+		return []string{"movies", "books"}, nil
+	}
+
+	require.Equal(t, []string{"movies", "books"}, c.get(completeIndexes, load))
+	require.Equal(t, []string{"movies", "books"}, c.get(completeIndexes, load))
+	require.Equal(t, 1, calls)
+
+	c.invalidate(completeKeys)
+	c.get(completeIndexes, load)
+	require.Equal(t, 1, calls)
+
+	c.invalidate(completeIndexes)
+	c.get(completeIndexes, load)
+	require.Equal(t, 2, calls)
+
+	c.invalidate()
+	c.get(completeIndexes, load)
+	require.Equal(t, 3, calls)
+}
+
+func TestCompletionCache_PendingTask(t *testing.T) {
+	finished := false
+	c := &completionCache{
+		entries:  make(map[string]completionEntry),
+		finished: func(uid int64) bool { return finished },
+	}
+
+	calls := 0
+	load := func() ([]string, error) {
+		calls++
+		return []string{"movies"}, nil
+	}
+
+	c.get(completeIndexes, load)
+	c.invalidateTask(1, completeIndexes)
+
+	// the index of the task is not created yet, nothing is cached until it is
+	c.get(completeIndexes, load)
+	c.get(completeIndexes, load)
+	require.Equal(t, 3, calls)
+
+	c.get(completeKeys, load)
+	c.get(completeKeys, load)
+	require.Equal(t, 4, calls)
+
+	finished = true
+	c.get(completeIndexes, load)
+	c.get(completeIndexes, load)
+	require.Equal(t, 5, calls)
+	require.Empty(t, c.pending)
+}
+
+func TestCompleteIndexUID_PendingTask(t *testing.T) {
+	f, sess := connectFake(t)
+	f.pause()
+
+	runLines(t, newRootCmd(sess), "index create songs")
+	uids, _ := sess.completeIndexUID(nil, nil, "")
+	require.Equal(t, []string{"books", "movies"}, uids)
+
+	// the index is created once the task is processed
+	f.mu.Lock()
+	task := f.tasks[len(f.tasks)-1]
+	f.process(task, func(*meilisearch.Task) *fakeError { return f.createIndexLocked("songs", "") })
+	f.mu.Unlock()
+
+	uids, _ = sess.completeIndexUID(nil, nil, "")
+	require.Equal(t, []string{"books", "movies", "songs"}, uids)
+}
+
+func TestCompletionCache_LoadError(t *testing.T) {
+	c := &completionCache{entries: make(map[string]completionEntry)}
+
+	require.Nil(t, c.get(completeTasks, func() ([]string, error) {
+		return nil, errors.New("unreachable")
+	}))
+	require.Empty(t, c.entries)
+}
+
+func TestSetValidArgs(t *testing.T) {
+	sess := NewSession(nil)
+	run := func(*cobra.Command, []string) {}
+	parent := &cobra.Command{Use: "settings"}
+	get := &cobra.Command{Use: "get", Run: run}
+	field := &cobra.Command{Use: "ranking-rules", Run: run}
+	own := &cobra.Command{Use: "own", Run: run, ValidArgsFunction: sess.completeKeyUID}
+	get.AddCommand(field)
+	parent.AddCommand(get, own)
+
+	setValidArgs(sess.completeIndexUID, parent)
+
+	require.Nil(t, parent.ValidArgsFunction)
+	require.NotNil(t, get.ValidArgsFunction)
+	require.NotNil(t, field.ValidArgsFunction)
+
+	_, directive := own.ValidArgsFunction(own, []string{"x"}, "")
+	require.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+}
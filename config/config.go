@@ -0,0 +1,188 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the named connection profiles of meilishell.
+type Config struct {
+	Current  string              `yaml:"current,omitempty"`
+	Profiles map[string]*Profile `yaml:"profiles"`
+
+	path string
+}
+
+// Profile describes how to connect to a Meilisearch server.
+type Profile struct {
+	Host    string        `yaml:"host"`
+	APIKey  string        `yaml:"api-key,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	TLS     *TLS          `yaml:"tls,omitempty"`
+}
+
+// TLS holds the TLS options of a profile.
+type TLS struct {
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify,omitempty"`
+	CACert             string `yaml:"ca-cert,omitempty"`
+	Cert               string `yaml:"cert,omitempty"`
+	Key                string `yaml:"key,omitempty"`
+}
+
+// DefaultPath returns the config file location, e.g. ~/.config/meilishell/config.yaml.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "meilishell", "config.yaml"), nil
+}
+
+// Load reads the config file at path. A missing file results in an empty config.
+func Load(path string) (*Config, error) {
+	c := &Config{
+		Profiles: make(map[string]*Profile),
+		path:     path,
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return c, nil
+		}
+		return c, err
+	}
+
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return c, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*Profile)
+	}
+
+	return c, nil
+}
+
+// Save writes the config file, creating its directory when needed.
+func (c *Config) Save() error {
+	if c.path == "" {
+		return errors.New("config file path is unknown")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	// profiles contain api keys, keep the file private
+	return os.WriteFile(c.path, b, 0o600)
+}
+
+// Path returns the location of the config file.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Names returns the sorted profile names.
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Profile returns the profile with the given name.
+func (c *Config) Profile(name string) (*Profile, bool) {
+	p, ok := c.Profiles[name]
+	return p, ok
+}
+
+// Add creates or replaces the profile with the given name.
+func (c *Config) Add(name string, p *Profile) error {
+	if name == "" {
+		return errors.New("profile name is required")
+	}
+
+	if p.Host == "" {
+		return errors.New("profile host is required")
+	}
+
+	c.Profiles[name] = p
+	return nil
+}
+
+// Remove deletes a profile, clearing the current profile if it was removed.
+func (c *Config) Remove(name string) error {
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+
+	delete(c.Profiles, name)
+	if c.Current == name {
+		c.Current = ""
+	}
+
+	return nil
+}
+
+// Use sets the profile used when meilishell starts.
+func (c *Config) Use(name string) error {
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+
+	c.Current = name
+	return nil
+}
+
+// TLSConfig builds the TLS client configuration of the profile, it is nil
+// when the profile has no TLS options.
+func (p *Profile) TLSConfig() (*tls.Config, error) {
+	if p.TLS == nil {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		InsecureSkipVerify: p.TLS.InsecureSkipVerify,
+	}
+
+	if p.TLS.CACert != "" {
+		b, err := os.ReadFile(p.TLS.CACert)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificate found in %s", p.TLS.CACert)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if p.TLS.Cert != "" || p.TLS.Key != "" {
+		cert, err := tls.LoadX509KeyPair(p.TLS.Cert, p.TLS.Key)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Missing(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+	require.NoError(t, err)
+	require.Empty(t, c.Profiles)
+	require.Empty(t, c.Current)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meilishell", "config.yaml")
+
+	c, err := Load(path)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Add("local", &Profile{Host: "http://localhost:7700"}))
+	require.NoError(t, c.Add("prod", &Profile{
+		Host:    "https://search.example.com",
+		APIKey:  "secret",
+		Timeout: 5 * time.Second,
+		TLS:     &TLS{InsecureSkipVerify: true},
+	}))
+	require.NoError(t, c.Use("prod"))
+	require.NoError(t, c.Save())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	c, err = Load(path)
+	require.NoError(t, err)
+	require.Equal(t, "prod", c.Current)
+	require.Equal(t, []string{"local", "prod"}, c.Names())
+
+	p, ok := c.Profile("prod")
+	require.True(t, ok)
+	require.Equal(t, 5*time.Second, p.Timeout)
+	require.True(t, p.TLS.InsecureSkipVerify)
+}
+
+func TestAdd_Invalid(t *testing.T) {
+	c := &Config{Profiles: make(map[string]*Profile)}
+	require.Error(t, c.Add("", &Profile{Host: "http://localhost:7700"}))
+	require.Error(t, c.Add("local", &Profile{}))
+}
+
+func TestRemove_ClearsCurrent(t *testing.T) {
+	c := &Config{Profiles: map[string]*Profile{"local": {Host: "http://localhost:7700"}}, Current: "local"}
+
+	require.NoError(t, c.Remove("local"))
+	require.Empty(t, c.Current)
+	require.Error(t, c.Remove("local"))
+	require.Error(t, c.Use("local"))
+}
+
+func TestTLSConfig(t *testing.T) {
+	cfg, err := (&Profile{}).TLSConfig()
+	require.NoError(t, err)
+	require.Nil(t, cfg)
+
+	cfg, err = (&Profile{TLS: &TLS{InsecureSkipVerify: true}}).TLSConfig()
+	require.NoError(t, err)
+	require.True(t, cfg.InsecureSkipVerify)
+
+	_, err = (&Profile{TLS: &TLS{CACert: filepath.Join(t.TempDir(), "missing.pem")}}).TLSConfig()
+	require.Error(t, err)
+}
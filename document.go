@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+)
+
+const (
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatCSV    = "csv"
+)
+
+func documentCmd(sess *Session) *cobra.Command {
+	doc := &cobra.Command{
+		Use:   "document",
+		Short: "manage documents",
+		Long:  "https://www.meilisearch.com/docs/reference/api/documents",
+	}
+
+	primaryKey := ""
+	format := ""
+
+	add := &cobra.Command{
+		Use:   "add",
+		Short: "add or replace documents from a file",
+		Long:  "document add movies movies.json --primary-key id",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageErrorf("index uid and file is require 'document add {uid} {file}'")
+			}
+
+			res, err := saveDocuments(sess.Client().Index(args[0]), args[1], format, primaryKey, false)
+			if err != nil {
+				return err
+			}
+
+			// the index is created when it does not exist
+			sess.completions.invalidateTask(res.TaskUID, completeIndexes, completeTasks)
+			sess.printTaskInfo(res)
+			return nil
+		},
+	}
+
+	add.Flags().StringVar(&primaryKey, "primary-key", "", "set primary key of the documents")
+	add.Flags().StringVar(&format, "format", "", "file format (json, ndjson, csv), detected from extension by default")
+
+	update := &cobra.Command{
+		Use:   "update",
+		Short: "add or update documents from a file",
+		Long:  "document update movies movies.ndjson --primary-key id",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageErrorf("index uid and file is require 'document update {uid} {file}'")
+			}
+
+			res, err := saveDocuments(sess.Client().Index(args[0]), args[1], format, primaryKey, true)
+			if err != nil {
+				return err
+			}
+
+			// the index is created when it does not exist
+			sess.completions.invalidateTask(res.TaskUID, completeIndexes, completeTasks)
+			sess.printTaskInfo(res)
+			return nil
+		},
+	}
+
+	update.Flags().StringVar(&primaryKey, "primary-key", "", "set primary key of the documents")
+	update.Flags().StringVar(&format, "format", "", "file format (json, ndjson, csv), detected from extension by default")
+
+	fields := make([]string, 0)
+
+	get := &cobra.Command{
+		Use:   "get",
+		Short: "get one document",
+		Long:  "document get movies 25684 --fields id,title",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageErrorf("index uid and document id is require 'document get {uid} {document_id}'")
+			}
+
+			res := make(map[string]interface{})
+			if err := sess.Client().Index(args[0]).GetDocument(args[1], &meilisearch.DocumentQuery{
+				Fields: fields,
+			}, &res); err != nil {
+				return err
+			}
+
+			sess.render(res, func() {
+				printJSON(res)
+			})
+			return nil
+		},
+	}
+
+	get.Flags().StringSliceVar(&fields, "fields", nil, "document attributes to show")
+
+	limit, offset := int64(0), int64(0)
+	filter := ""
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "list documents",
+		Long:  "document list movies --limit 10 --filter \"genres = action\"",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("index uid is require 'document list {uid}'")
+			}
+
+			req := &meilisearch.DocumentsQuery{
+				Limit:  limit,
+				Offset: offset,
+				Fields: fields,
+			}
+
+			if len(filter) != 0 {
+				req.Filter = filter
+			}
+
+			res := new(meilisearch.DocumentsResult)
+			if err := sess.Client().Index(args[0]).GetDocuments(req, res); err != nil {
+				return err
+			}
+
+			sess.renderWith(res, res.Results, func() {
+				for _, d := range res.Results {
+					printJSON(d)
+					lineBreaker()
+				}
+
+				fmt.Printf("Offset: %d\nLimit: %d\nTotal: %d\n", res.Offset, res.Limit, res.Total)
+			}, fields...)
+			return nil
+		},
+	}
+
+	list.Flags().StringSliceVar(&fields, "fields", nil, "document attributes to show")
+	list.Flags().Int64Var(&limit, "limit", 0, "set limit for list of documents")
+	list.Flags().Int64Var(&offset, "offset", 0, "set offset for list of documents")
+	list.Flags().StringVar(&filter, "filter", "", "filter documents by an attribute's value")
+
+	del := &cobra.Command{
+		Use:   "delete",
+		Short: "delete one or many documents",
+		Long: `document delete movies 1
+document delete movies 1 2 3 4
+document delete movies --filter "genres = horror"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("index uid is require 'document delete {uid} {document_id or many 1 2 3 4}'")
+			}
+
+			idx := sess.Client().Index(args[0])
+			ids := args[1:]
+
+			var (
+				res *meilisearch.TaskInfo
+				err error
+			)
+
+			switch {
+			case len(filter) != 0:
+				res, err = idx.DeleteDocumentsByFilter(filter)
+			case len(ids) == 1:
+				res, err = idx.DeleteDocument(ids[0])
+			case len(ids) > 1:
+				res, err = idx.DeleteDocuments(ids)
+			default:
+				return usageErrorf("document id or --filter is require 'document delete {uid} {document_id}'")
+			}
+
+			if err != nil {
+				return err
+			}
+
+			sess.printTaskInfo(res)
+			return nil
+		},
+	}
+
+	del.Flags().StringVar(&filter, "filter", "", "delete documents matching the filter")
+
+	delAll := &cobra.Command{
+		Use:   "delete-all",
+		Short: "delete all documents of an index",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("index uid is require 'document delete-all {uid}'")
+			}
+
+			res, err := sess.Client().Index(args[0]).DeleteAllDocuments()
+			if err != nil {
+				return err
+			}
+
+			sess.printTaskInfo(res)
+			return nil
+		},
+	}
+
+	doc.AddCommand(add)
+	doc.AddCommand(update)
+	doc.AddCommand(get)
+	doc.AddCommand(list)
+	doc.AddCommand(del)
+	doc.AddCommand(delAll)
+	doc.AddCommand(documentImportCmd(sess))
+	doc.AddCommand(documentExportCmd(sess))
+
+	setValidArgs(sess.completeIndexUID, doc.Commands()...)
+
+	return doc
+}
+
+// saveDocuments sends the content of file to the index, replacing documents
+// or updating them when update is set.
+func saveDocuments(idx *meilisearch.Index, file, format, primaryKey string, update bool) (*meilisearch.TaskInfo, error) {
+	if len(format) == 0 {
+		format = documentFormat(file)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var pk []string
+	if len(primaryKey) != 0 {
+		pk = append(pk, primaryKey)
+	}
+
+	switch format {
+	case formatJSON:
+		if update {
+			return idx.UpdateDocuments(data, pk...)
+		}
+		return idx.AddDocuments(data, pk...)
+	case formatNDJSON:
+		if update {
+			return idx.UpdateDocumentsNdjson(data, pk...)
+		}
+		return idx.AddDocumentsNdjson(data, pk...)
+	case formatCSV:
+		opts := &meilisearch.CsvDocumentsQuery{PrimaryKey: primaryKey}
+		if update {
+			return idx.UpdateDocumentsCsv(data, opts)
+		}
+		return idx.AddDocumentsCsv(data, opts)
+	default:
+		return nil, fmt.Errorf("unsupported document format %q, use json, ndjson or csv", format)
+	}
+}
+
+func documentFormat(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".ndjson", ".jsonl":
+		return formatNDJSON
+	case ".csv":
+		return formatCSV
+	default:
+		return formatJSON
+	}
+}
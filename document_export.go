@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func documentExportCmd(sess *Session) *cobra.Command {
+	format := ""
+	out := ""
+	filter := ""
+	fields := make([]string, 0)
+	pageSize := int64(0)
+
+	exp := &cobra.Command{
+		Use:   "export",
+		Short: "export documents of an index to a file",
+		Long: `document export movies --format ndjson --out movies.ndjson
+document export movies --format csv --fields id,title --filter "year > 2000" --out movies.csv`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("index uid is require 'document export {uid} --out {file}'")
+			}
+
+			if pageSize <= 0 {
+				return usageErrorf("page size must be greater than zero")
+			}
+
+			idx := sess.Client().Index(args[0])
+
+			if len(format) == 0 {
+				format = formatNDJSON
+				if len(out) != 0 {
+					format = documentFormat(out)
+				}
+			}
+
+			columns := fields
+			if format == formatCSV && len(columns) == 0 {
+				stats, err := idx.GetStats()
+				if err != nil {
+					return err
+				}
+
+				for field := range stats.FieldDistribution {
+					columns = append(columns, field)
+				}
+				sort.Strings(columns)
+			}
+
+			var w io.Writer = os.Stdout
+			if len(out) != 0 && out != "-" {
+				f, err := os.Create(out)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			bw := bufio.NewWriter(w)
+			dw, err := newDocumentWriter(format, bw, columns)
+			if err != nil {
+				return err
+			}
+
+			exported, err := exportDocuments(sess, args[0], dw, &meilisearch.DocumentsQuery{
+				Limit:  pageSize,
+				Fields: fields,
+			}, filter)
+
+			if cerr := dw.close(); err == nil {
+				err = cerr
+			}
+			if ferr := bw.Flush(); err == nil {
+				err = ferr
+			}
+
+			if exportProgress {
+				fmt.Fprintln(os.Stderr)
+			}
+			if err != nil {
+				return err
+			}
+
+			// stdout may be the exported documents, the summary goes with the progress
+			fmt.Fprintf(os.Stderr, "exported %d documents\n", exported)
+			return nil
+		},
+	}
+
+	exp.Flags().StringVar(&format, "format", "", "output format (ndjson, json, csv), detected from --out by default")
+	exp.Flags().StringVar(&out, "out", "", "output file, stdout by default")
+	exp.Flags().StringVar(&filter, "filter", "", "export only documents matching the filter")
+	exp.Flags().StringSliceVar(&fields, "fields", nil, "document attributes to export")
+	exp.Flags().Int64Var(&pageSize, "page-size", 1000, "number of documents fetched per request")
+
+	return exp
+}
+
+// exportProgress is the exported documents line, drawn only on a terminal so
+// piped stderr and CI logs are not filled with carriage returns.
+var exportProgress = term.IsTerminal(int(os.Stderr.Fd()))
+
+// exportDocuments pages through the documents of index uid and writes them to
+// w, returning the number of written documents. The documents are decoded with
+// json.Number, so large integer ids are written as they are stored.
+func exportDocuments(sess *Session, uid string, w documentWriter, query *meilisearch.DocumentsQuery, filter string) (int64, error) {
+	exported := int64(0)
+
+	for {
+		res, err := fetchDocuments(sess, uid, query, filter)
+		if err != nil {
+			return exported, err
+		}
+
+		for _, d := range res.Results {
+			if err := w.write(d); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+
+		if exportProgress {
+			fmt.Fprintf(os.Stderr, "\rexported %d/%d documents", exported, res.Total)
+		}
+
+		query.Offset += int64(len(res.Results))
+		if len(res.Results) == 0 || query.Offset >= res.Total {
+			return exported, nil
+		}
+	}
+}
+
+type documentsPage struct {
+	Results []map[string]interface{} `json:"results"`
+	Total   int64                    `json:"total"`
+}
+
+// fetchDocuments gets a page of documents like the client, which fetches them
+// with POST when there is a filter.
+func fetchDocuments(sess *Session, uid string, query *meilisearch.DocumentsQuery, filter string) (*documentsPage, error) {
+	endpoint := "/indexes/" + url.PathEscape(uid) + "/documents"
+
+	var body []byte
+	method := http.MethodGet
+	if len(filter) != 0 {
+		req := map[string]interface{}{"offset": query.Offset, "limit": query.Limit, "filter": filter}
+		if len(query.Fields) != 0 {
+			req["fields"] = query.Fields
+		}
+
+		b, err := json.Marshal(req)
+		if err != nil {
+			return nil, err
+		}
+		body, method, endpoint = b, http.MethodPost, endpoint+"/fetch"
+	} else {
+		params := url.Values{}
+		params.Set("offset", strconv.FormatInt(query.Offset, 10))
+		params.Set("limit", strconv.FormatInt(query.Limit, 10))
+		if len(query.Fields) != 0 {
+			params.Set("fields", strings.Join(query.Fields, ","))
+		}
+		endpoint += "?" + params.Encode()
+	}
+
+	b, err := sess.rawRequest("GetDocuments", method, endpoint, body, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	page := new(documentsPage)
+	if err := dec.Decode(page); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+type documentWriter interface {
+	write(doc map[string]interface{}) error
+	close() error
+}
+
+func newDocumentWriter(format string, w io.Writer, columns []string) (documentWriter, error) {
+	switch format {
+	case formatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case formatJSON:
+		return &jsonWriter{w: w}, nil
+	case formatCSV:
+		cw := &csvWriter{w: csv.NewWriter(w), columns: columns}
+		if err := cw.w.Write(columns); err != nil {
+			return nil, err
+		}
+		return cw, nil
+	default:
+		return nil, usageErrorf("unsupported document format %q, use json, ndjson or csv", format)
+	}
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) write(doc map[string]interface{}) error {
+	return n.enc.Encode(doc)
+}
+
+func (n *ndjsonWriter) close() error {
+	return nil
+}
+
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) write(doc map[string]interface{}) error {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if j.count == 0 {
+		sep = "[\n"
+	}
+	j.count++
+
+	if _, err := io.WriteString(j.w, sep); err != nil {
+		return err
+	}
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *jsonWriter) close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func (c *csvWriter) write(doc map[string]interface{}) error {
+	rec := make([]string, len(c.columns))
+
+	for i, col := range c.columns {
+		switch v := doc[col].(type) {
+		case nil:
+		case string:
+			rec[i] = v
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			rec[i] = string(b)
+		}
+	}
+
+	return c.w.Write(rec)
+}
+
+func (c *csvWriter) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDocumentWriter(t *testing.T) {
+	docs := []map[string]interface{}{
+		{"id": 1, "title": "dune", "genres": []string{"sf", "novel"}},
+		{"id": 2, "title": "emma, a novel"},
+	}
+
+	write := func(format string, columns []string, docs []map[string]interface{}) string {
+		buf := new(bytes.Buffer)
+		dw, err := newDocumentWriter(format, buf, columns)
+		require.NoError(t, err)
+		for _, d := range docs {
+			require.NoError(t, dw.write(d))
+		}
+		require.NoError(t, dw.close())
+		return buf.String()
+	}
+
+	require.Equal(t, "{\"genres\":[\"sf\",\"novel\"],\"id\":1,\"title\":\"dune\"}\n{\"id\":2,\"title\":\"emma, a novel\"}\n",
+		write(formatNDJSON, nil, docs))
+
+	require.Equal(t, "[\n{\"genres\":[\"sf\",\"novel\"],\"id\":1,\"title\":\"dune\"},\n{\"id\":2,\"title\":\"emma, a novel\"}\n]\n",
+		write(formatJSON, nil, docs))
+	require.Equal(t, "[]\n", write(formatJSON, nil, nil))
+
+	require.Equal(t, "id,title,genres\n1,dune,\"[\"\"sf\"\",\"\"novel\"\"]\"\n2,\"emma, a novel\",\n",
+		write(formatCSV, []string{"id", "title", "genres"}, docs))
+	require.Equal(t, "id\n", write(formatCSV, []string{"id"}, nil))
+
+	_, err := newDocumentWriter("xml", new(bytes.Buffer), nil)
+	require.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestDocumentExportCmd(t *testing.T) {
+	f, sess := connectFake(t)
+	f.indexes["movies"].documents = []string{`{"id":9007199254740993,"title":"dune"}`, `{"id":2,"title":"emma"}`}
+
+	dir := t.TempDir()
+	out := runLines(t, newRootCmd(sess),
+		"document export movies --page-size 1 --out "+filepath.Join(dir, "movies.ndjson"),
+		`document export movies --filter "year > 2000" --fields id --out `+filepath.Join(dir, "movies.csv"),
+	)
+	require.NotContains(t, out, "error:")
+
+	// large ids are kept as they are stored
+	b, err := os.ReadFile(filepath.Join(dir, "movies.ndjson"))
+	require.NoError(t, err)
+	require.Equal(t, "{\"id\":9007199254740993,\"title\":\"dune\"}\n{\"id\":2,\"title\":\"emma\"}\n", string(b))
+
+	b, err = os.ReadFile(filepath.Join(dir, "movies.csv"))
+	require.NoError(t, err)
+	require.Equal(t, "id\n9007199254740993\n2\n", string(b))
+
+	require.Equal(t, map[string]interface{}{
+		"offset": float64(0), "limit": float64(1000), "filter": "year > 2000", "fields": []interface{}{"id"},
+	}, f.body(t, "POST", "/indexes/movies/documents/fetch"))
+}
@@ -0,0 +1,595 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Ja7ad/meilishell/util"
+	"github.com/fatih/color"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func documentImportCmd(sess *Session) *cobra.Command {
+	primaryKey := ""
+	format := ""
+	batchSize := 0
+	batchBytes := ""
+	concurrency := 0
+	waitTimeout := time.Duration(0)
+	resume := false
+
+	imp := &cobra.Command{
+		Use:   "import",
+		Short: "stream a large file into an index in batches",
+		Long: `document import movies movies.ndjson --batch-size 5000 --concurrency 4
+document import movies movies.ndjson --batch-bytes 50MB --resume`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageErrorf("index uid and file is require 'document import {uid} {file}'")
+			}
+
+			maxBytes := uint64(0)
+			if len(batchBytes) != 0 {
+				v, err := util.ParseHumanReadableBytes(batchBytes)
+				if err != nil {
+					return err
+				}
+				maxBytes = v
+			}
+
+			if len(format) == 0 {
+				format = documentFormat(args[1])
+			}
+
+			im := &importer{
+				index:       sess.Client().Index(args[0]),
+				file:        args[1],
+				format:      format,
+				primaryKey:  primaryKey,
+				batchSize:   batchSize,
+				batchBytes:  int(maxBytes),
+				concurrency: concurrency,
+				waitTimeout: waitTimeout,
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			summary, err := im.run(ctx, resume)
+			// the batches create the index when it does not exist
+			sess.completions.invalidate(completeIndexes, completeTasks)
+			if summary != nil {
+				sess.render(summary, func() {
+					plainImportSummary(summary)
+				}, "importedDocuments", "batches", "failedBatches", "duration", "complete")
+			}
+			return err
+		},
+	}
+
+	imp.Flags().StringVar(&primaryKey, "primary-key", "", "set primary key of the documents")
+	imp.Flags().StringVar(&format, "format", "", "file format (json, ndjson, csv), detected from extension by default")
+	imp.Flags().IntVar(&batchSize, "batch-size", 1000, "maximum number of documents per batch")
+	imp.Flags().StringVar(&batchBytes, "batch-bytes", "", "maximum size of a batch, e.g. 50MB")
+	imp.Flags().IntVar(&concurrency, "concurrency", 1, "number of batches sent at the same time")
+	imp.Flags().DurationVar(&waitTimeout, "wait-timeout", 10*time.Minute, "maximum time to wait for each batch task")
+	imp.Flags().BoolVar(&resume, "resume", false, "continue from the last successful batch of a previous import")
+
+	return imp
+}
+
+type importBatch struct {
+	seq  int
+	docs int
+	data []byte
+}
+
+type importResult struct {
+	batch   importBatch
+	taskUID int64
+	err     error
+}
+
+// importState is persisted next to the imported file so an interrupted
+// import can skip the documents which are already indexed. The format, size
+// and modification time of the file make sure they are the same documents.
+type importState struct {
+	Index         string    `json:"index"`
+	File          string    `json:"file"`
+	Format        string    `json:"format"`
+	Size          int64     `json:"size"`
+	ModTime       time.Time `json:"modTime"`
+	CompletedDocs int64     `json:"completedDocs"`
+}
+
+type importSummary struct {
+	ImportedDocuments int64           `json:"importedDocuments"`
+	Batches           int             `json:"batches"`
+	FailedBatches     int             `json:"failedBatches"`
+	Failures          []importFailure `json:"failures"`
+	Duration          string          `json:"duration"`
+	Complete          bool            `json:"complete"`
+	CompletedDocs     int64           `json:"completedDocs"`
+}
+
+type importFailure struct {
+	Batch   int    `json:"batch"`
+	TaskUID int64  `json:"taskUid"`
+	Error   string `json:"error"`
+}
+
+type importer struct {
+	index       *meilisearch.Index
+	file        string
+	format      string
+	primaryKey  string
+	batchSize   int
+	batchBytes  int
+	concurrency int
+	waitTimeout time.Duration
+
+	readBytes atomic.Int64
+	sentDocs  atomic.Int64
+}
+
+func (im *importer) statePath() string {
+	return im.file + ".meilishell-import"
+}
+
+// run imports the file, the summary is returned as soon as batches were sent,
+// together with the error of an incomplete import.
+func (im *importer) run(ctx context.Context, resume bool) (*importSummary, error) {
+	if im.batchSize <= 0 {
+		return nil, usageErrorf("batch size must be greater than zero")
+	}
+
+	if im.concurrency <= 0 {
+		im.concurrency = 1
+	}
+
+	f, err := os.Open(im.file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &importState{
+		Index:   im.index.UID,
+		File:    im.file,
+		Format:  im.format,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+	if resume {
+		state, err = im.loadState(state)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "resuming import after %d documents\n", state.CompletedDocs)
+	}
+
+	next, header, err := newDocumentReader(im.format, &countingReader{r: f, n: &im.readBytes})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := int64(0); i < state.CompletedDocs; i++ {
+		if _, err := next(); err != nil {
+			return nil, fmt.Errorf("skip imported documents: %w", err)
+		}
+	}
+
+	batches := make(chan importBatch)
+	results := make(chan importResult)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < im.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				results <- im.send(ctx, b)
+			}
+		}()
+	}
+
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(batches)
+		readErr <- im.readBatches(ctx, next, header, batches)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	started := time.Now()
+	stop := im.startProgress(info.Size(), started)
+
+	pending := make(map[int]importResult)
+	failed := make([]importResult, 0)
+	nextSeq := 0
+	blocked := false
+
+	for res := range results {
+		if res.err != nil {
+			failed = append(failed, res)
+		}
+
+		pending[res.batch.seq] = res
+		for !blocked {
+			r, ok := pending[nextSeq]
+			if !ok {
+				break
+			}
+			if r.err != nil {
+				blocked = true
+				break
+			}
+
+			state.CompletedDocs += int64(r.batch.docs)
+			delete(pending, nextSeq)
+			nextSeq++
+
+			if err := im.saveState(state); err != nil {
+				color.Red("%s", err.Error())
+			}
+		}
+	}
+
+	stop()
+
+	readFailed := <-readErr
+
+	summary := &importSummary{
+		ImportedDocuments: im.sentDocs.Load(),
+		Batches:           nextSeq + len(pending),
+		FailedBatches:     len(failed),
+		Failures:          make([]importFailure, 0, len(failed)),
+		Duration:          time.Since(started).Round(time.Millisecond).String(),
+		Complete:          len(failed) == 0 && ctx.Err() == nil && readFailed == nil,
+		CompletedDocs:     state.CompletedDocs,
+	}
+
+	for _, f := range failed {
+		summary.Failures = append(summary.Failures, importFailure{
+			Batch:   f.batch.seq + 1,
+			TaskUID: f.taskUID,
+			Error:   f.err.Error(),
+		})
+	}
+
+	switch {
+	case readFailed != nil:
+		return summary, readFailed
+	case len(failed) != 0:
+		return summary, fmt.Errorf("%d of %d batches failed", len(failed), summary.Batches)
+	case ctx.Err() != nil:
+		return summary, ctx.Err()
+	}
+
+	_ = os.Remove(im.statePath())
+	return summary, nil
+}
+
+func plainImportSummary(s *importSummary) {
+	fmt.Printf(`Imported Documents: %d
+Batches: %d
+Failed Batches: %d
+Duration: %s
+`, s.ImportedDocuments, s.Batches, s.FailedBatches, s.Duration)
+
+	for _, f := range s.Failures {
+		color.Red("batch %d (task %d): %s", f.Batch, f.TaskUID, f.Error)
+	}
+
+	if !s.Complete {
+		color.Yellow("import is incomplete, run again with --resume to continue after document %d", s.CompletedDocs)
+	}
+}
+
+func (im *importer) readBatches(ctx context.Context, next func() ([]byte, error), header []byte, out chan<- importBatch) error {
+	seq := 0
+	items := make([][]byte, 0, im.batchSize)
+	size := 0
+
+	flush := func() bool {
+		if len(items) == 0 {
+			return true
+		}
+
+		b := importBatch{seq: seq, docs: len(items), data: encodeBatch(im.format, header, items)}
+		seq++
+		items = make([][]byte, 0, im.batchSize)
+		size = 0
+
+		select {
+		case out <- b:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		doc, err := next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				flush()
+				return nil
+			}
+			flush()
+			return err
+		}
+
+		if im.batchBytes > 0 && len(items) != 0 && size+len(doc) > im.batchBytes {
+			if !flush() {
+				return nil
+			}
+		}
+
+		items = append(items, doc)
+		size += len(doc)
+
+		if len(items) >= im.batchSize {
+			if !flush() {
+				return nil
+			}
+		}
+	}
+}
+
+func (im *importer) send(ctx context.Context, b importBatch) importResult {
+	res := importResult{batch: b}
+
+	var pk []string
+	if len(im.primaryKey) != 0 {
+		pk = append(pk, im.primaryKey)
+	}
+
+	var info *meilisearch.TaskInfo
+	switch im.format {
+	case formatNDJSON:
+		info, res.err = im.index.AddDocumentsNdjson(b.data, pk...)
+	case formatCSV:
+		info, res.err = im.index.AddDocumentsCsv(b.data, &meilisearch.CsvDocumentsQuery{PrimaryKey: im.primaryKey})
+	default:
+		info, res.err = im.index.AddDocuments(b.data, pk...)
+	}
+
+	if res.err != nil {
+		return res
+	}
+
+	res.taskUID = info.TaskUID
+
+	// an interrupt stops the wait, the batch is sent again on resume
+	ctx, cancel := context.WithTimeout(ctx, im.waitTimeout)
+	defer cancel()
+
+	t, err := im.index.WaitForTask(info.TaskUID, meilisearch.WaitParams{
+		Context:  ctx,
+		Interval: 500 * time.Millisecond,
+	})
+	if err != nil {
+		res.err = err
+		return res
+	}
+
+	if t.Status != meilisearch.TaskStatusSucceeded {
+		res.err = fmt.Errorf("task %s: %s", t.Status, t.Error.Message)
+		return res
+	}
+
+	im.sentDocs.Add(int64(b.docs))
+	return res
+}
+
+// startProgress draws a progress bar on stderr until the returned func is called,
+// only on a terminal so logs are not flooded.
+func (im *importer) startProgress(total int64, started time.Time) func() {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	draw := func() {
+		read := im.readBytes.Load()
+		percent := 100.0
+		if total > 0 {
+			percent = float64(read) * 100 / float64(total)
+		}
+
+		width := 30
+		filled := int(percent / 100 * float64(width))
+		if filled > width {
+			filled = width
+		}
+
+		rate := float64(im.sentDocs.Load()) / time.Since(started).Seconds()
+		fmt.Fprintf(os.Stderr, "\r[%s%s] %5.1f%% %s/%s %d docs %.0f docs/s ",
+			strings.Repeat("=", filled), strings.Repeat(" ", width-filled), percent,
+			util.FormatBytesToHumanReadable(uint64(read)), util.FormatBytesToHumanReadable(uint64(total)),
+			im.sentDocs.Load(), rate)
+	}
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				draw()
+				fmt.Fprintln(os.Stderr)
+				return
+			case <-ticker.C:
+				draw()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// loadState returns the state of the previous import, which must match the
+// index, format and file fingerprint of current.
+func (im *importer) loadState(current *importState) (*importState, error) {
+	b, err := os.ReadFile(im.statePath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, usageErrorf("no previous import found for %s", im.file)
+		}
+		return nil, err
+	}
+
+	state := new(importState)
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case state.Index != current.Index:
+		return nil, usageErrorf("previous import of %s was into index %s", im.file, state.Index)
+	case state.Format != current.Format:
+		return nil, usageErrorf("previous import of %s was in %s format, not %s", im.file, state.Format, current.Format)
+	case state.Size != current.Size || !state.ModTime.Equal(current.ModTime):
+		return nil, usageErrorf("%s changed since the previous import, run it again without --resume", im.file)
+	}
+
+	return state, nil
+}
+
+func (im *importer) saveState(state *importState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(im.statePath(), b, 0o600)
+}
+
+// newDocumentReader returns a function yielding one encoded document per call
+// and, for csv, the encoded header which has to prefix every batch.
+func newDocumentReader(format string, r io.Reader) (func() ([]byte, error), []byte, error) {
+	switch format {
+	case formatNDJSON:
+		br := bufio.NewReaderSize(r, 1<<20)
+		return func() ([]byte, error) {
+			for {
+				line, err := br.ReadBytes('\n')
+				line = bytes.TrimSpace(line)
+				if len(line) != 0 {
+					return line, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+		}, nil, nil
+	case formatCSV:
+		cr := csv.NewReader(r)
+		header, err := cr.Read()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read csv header: %w", err)
+		}
+
+		encode := func(rec []string) ([]byte, error) {
+			buf := new(bytes.Buffer)
+			w := csv.NewWriter(buf)
+			if err := w.Write(rec); err != nil {
+				return nil, err
+			}
+			w.Flush()
+			return buf.Bytes(), w.Error()
+		}
+
+		h, err := encode(header)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return func() ([]byte, error) {
+			rec, err := cr.Read()
+			if err != nil {
+				return nil, err
+			}
+			return encode(rec)
+		}, h, nil
+	case formatJSON:
+		dec := json.NewDecoder(bufio.NewReaderSize(r, 1<<20))
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		if d, ok := tok.(json.Delim); !ok || d != '[' {
+			return nil, nil, usageErrorf("json documents must be an array")
+		}
+
+		return func() ([]byte, error) {
+			if !dec.More() {
+				return nil, io.EOF
+			}
+			raw := json.RawMessage{}
+			if err := dec.Decode(&raw); err != nil {
+				return nil, err
+			}
+			return raw, nil
+		}, nil, nil
+	default:
+		return nil, nil, usageErrorf("unsupported document format %q, use json, ndjson or csv", format)
+	}
+}
+
+func encodeBatch(format string, header []byte, items [][]byte) []byte {
+	switch format {
+	case formatNDJSON:
+		return bytes.Join(items, []byte("\n"))
+	case formatCSV:
+		return append(append([]byte{}, header...), bytes.Join(items, nil)...)
+	default:
+		return append(append([]byte("["), bytes.Join(items, []byte(","))...), ']')
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
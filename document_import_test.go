@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeBatch(t *testing.T) {
+	items := [][]byte{[]byte(`{"id":1}`), []byte(`{"id":2}`)}
+
+	require.Equal(t, `[{"id":1},{"id":2}]`, string(encodeBatch(formatJSON, nil, items)))
+	require.Equal(t, "{\"id\":1}\n{\"id\":2}", string(encodeBatch(formatNDJSON, nil, items)))
+	require.Equal(t, "id,title\n1,dune\n2,emma\n",
+		string(encodeBatch(formatCSV, []byte("id,title\n"), [][]byte{[]byte("1,dune\n"), []byte("2,emma\n")})))
+}
+
+func TestImporter_ReadBatches(t *testing.T) {
+	input := "{\"id\":1}\n\n{\"id\":2}\n{\"id\":3}\n{\"id\":4}\n{\"id\":5}\n"
+
+	batches := func(im *importer) []importBatch {
+		next, header, err := newDocumentReader(formatNDJSON, strings.NewReader(input))
+		require.NoError(t, err)
+
+		out := make(chan importBatch)
+		got := make([]importBatch, 0)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for b := range out {
+				got = append(got, b)
+			}
+		}()
+
+		require.NoError(t, im.readBatches(context.Background(), next, header, out))
+		close(out)
+		<-done
+		return got
+	}
+
+	got := batches(&importer{format: formatNDJSON, batchSize: 2})
+	require.Len(t, got, 3)
+	require.Equal(t, []int{2, 2, 1}, []int{got[0].docs, got[1].docs, got[2].docs})
+	require.Equal(t, 2, got[2].seq)
+	require.Equal(t, `{"id":5}`, string(got[2].data))
+
+	// a batch is flushed before it grows over the byte limit
+	got = batches(&importer{format: formatNDJSON, batchSize: 10, batchBytes: 20})
+	require.Len(t, got, 3)
+	require.Equal(t, "{\"id\":1}\n{\"id\":2}", string(got[0].data))
+}
+
+func TestNewDocumentReader(t *testing.T) {
+	next, header, err := newDocumentReader(formatCSV, strings.NewReader("id,title\n1,\"dune, messiah\"\n"))
+	require.NoError(t, err)
+	require.Equal(t, "id,title\n", string(header))
+
+	doc, err := next()
+	require.NoError(t, err)
+	require.Equal(t, "1,\"dune, messiah\"\n", string(doc))
+
+	next, _, err = newDocumentReader(formatJSON, strings.NewReader(`[{"id": 1}, {"id": 2}]`))
+	require.NoError(t, err)
+	doc, err = next()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id": 1}`, string(doc))
+
+	_, _, err = newDocumentReader(formatJSON, strings.NewReader(`{"id": 1}`))
+	require.Equal(t, exitUsage, exitCode(err))
+
+	_, _, err = newDocumentReader("xml", strings.NewReader(""))
+	require.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestImporter_Resume(t *testing.T) {
+	f, sess := connectFake(t)
+
+	file := filepath.Join(t.TempDir(), "movies.ndjson")
+	require.NoError(t, os.WriteFile(file, []byte("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n{\"id\":4}\n{\"id\":5}\n"), 0o600))
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+
+	newImporter := func(format string) *importer {
+		return &importer{
+			index:       sess.Client().Index("movies"),
+			file:        file,
+			format:      format,
+			batchSize:   2,
+			concurrency: 1,
+			waitTimeout: time.Minute,
+		}
+	}
+
+	im := newImporter(formatNDJSON)
+	require.NoError(t, im.saveState(&importState{
+		Index:         "movies",
+		File:          file,
+		Format:        formatNDJSON,
+		Size:          info.Size(),
+		ModTime:       info.ModTime(),
+		CompletedDocs: 3,
+	}))
+
+	// the state is kept when the resume is refused
+	_, err = newImporter(formatJSON).run(context.Background(), true)
+	require.Equal(t, exitUsage, exitCode(err))
+	require.Contains(t, err.Error(), "was in ndjson format")
+
+	summary, err := im.run(context.Background(), true)
+	require.NoError(t, err)
+	require.True(t, summary.Complete)
+	require.Equal(t, int64(2), summary.ImportedDocuments)
+	require.Equal(t, []string{`{"id":4}`, `{"id":5}`}, f.indexes["movies"].documents)
+
+	_, err = os.Stat(im.statePath())
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = newImporter(formatNDJSON).run(context.Background(), true)
+	require.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestImporter_ResumeChangedFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "movies.ndjson")
+	require.NoError(t, os.WriteFile(file, []byte("{\"id\":1}\n"), 0o600))
+
+	im := &importer{file: file, format: formatNDJSON}
+	b, err := json.Marshal(&importState{Index: "movies", File: file, Format: formatNDJSON, Size: 9,
+		ModTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), CompletedDocs: 1})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(im.statePath(), b, 0o600))
+
+	_, err = im.loadState(&importState{Index: "movies", File: file, Format: formatNDJSON, Size: 9,
+		ModTime: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)})
+	require.Equal(t, exitUsage, exitCode(err))
+	require.Contains(t, err.Error(), "changed since the previous import")
+}
+
+func TestImporter_InterruptStopsWaiting(t *testing.T) {
+	f, sess := connectFake(t)
+	f.pause()
+
+	file := filepath.Join(t.TempDir(), "movies.ndjson")
+	require.NoError(t, os.WriteFile(file, []byte("{\"id\":1}\n{\"id\":2}\n"), 0o600))
+
+	im := &importer{
+		index:       sess.Client().Index("movies"),
+		file:        file,
+		format:      formatNDJSON,
+		batchSize:   1,
+		concurrency: 2,
+		waitTimeout: time.Minute,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	summary, err := im.run(ctx, false)
+	require.Error(t, err)
+	require.False(t, summary.Complete)
+	require.Less(t, time.Since(started), 10*time.Second)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/fatih/color"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+)
+
+// exit codes of meilishell, scripts can tell the kind of failure apart with them
+const (
+	exitOK         = 0
+	exitFailure    = 1
+	exitUsage      = 2
+	exitAuth       = 3
+	exitNotFound   = 4
+	exitValidation = 5
+	exitNetwork    = 6
+)
+
+// usageError is returned when a command is called with missing or invalid arguments.
+type usageError struct {
+	msg string
+}
+
+func usageErrorf(format string, a ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, a...)}
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// apiError is a failed request to Meilisearch, or a failed task.
+type apiError struct {
+	StatusCode int
+	Code       string
+	Type       string
+	Message    string
+	Link       string
+
+	err *meilisearch.Error
+}
+
+func newAPIError(err *meilisearch.Error) *apiError {
+	return &apiError{
+		StatusCode: err.StatusCode,
+		Code:       err.MeilisearchApiError.Code,
+		Type:       err.MeilisearchApiError.Type,
+		Message:    err.MeilisearchApiError.Message,
+		Link:       err.MeilisearchApiError.Link,
+		err:        err,
+	}
+}
+
+// newTaskError returns the error of a failed or canceled task.
+func newTaskError(t *meilisearch.Task) *apiError {
+	if t.Status == meilisearch.TaskStatusCanceled {
+		return &apiError{Message: fmt.Sprintf("task %d was canceled by task %d", t.UID, t.CanceledBy)}
+	}
+
+	return &apiError{
+		Code:    t.Error.Code,
+		Type:    t.Error.Type,
+		Message: fmt.Sprintf("task %d failed: %s", t.UID, t.Error.Message),
+		Link:    t.Error.Link,
+	}
+}
+
+// wrapError turns the errors of the Meilisearch client into an apiError,
+// other errors are returned as they are.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var ae *apiError
+	if errors.As(err, &ae) {
+		return err
+	}
+
+	var me *meilisearch.Error
+	if errors.As(err, &me) {
+		return newAPIError(me)
+	}
+
+	return err
+}
+
+func (e *apiError) Error() string {
+	switch {
+	case e.network():
+		if e.err.OriginError != nil {
+			return fmt.Sprintf("failed to reach Meilisearch: %s", e.err.OriginError.Error())
+		}
+		return "failed to reach Meilisearch"
+	case e.StatusCode == 0 && len(e.Code) != 0:
+		return fmt.Sprintf("%s (%s)", e.Message, e.Code)
+	case len(e.Message) != 0 && len(e.Code) != 0:
+		return fmt.Sprintf("%s (%s, status %d)", e.Message, e.Code, e.StatusCode)
+	case len(e.Message) != 0 && e.StatusCode != 0:
+		return fmt.Sprintf("%s (status %d)", e.Message, e.StatusCode)
+	case e.err == nil:
+		return e.Message
+	}
+
+	return e.err.Error()
+}
+
+func (e *apiError) Unwrap() error {
+	if e.err == nil {
+		return nil
+	}
+	return e.err
+}
+
+func (e *apiError) network() bool {
+	if e.err == nil {
+		return false
+	}
+
+	return e.err.ErrCode == meilisearch.MeilisearchCommunicationError ||
+		e.err.ErrCode == meilisearch.MeilisearchTimeoutError
+}
+
+// ExitCode maps the error to the exit code of meilishell.
+func (e *apiError) ExitCode() int {
+	switch {
+	case e.network():
+		return exitNetwork
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return exitAuth
+	case e.StatusCode == http.StatusNotFound:
+		return exitNotFound
+	case e.StatusCode >= 400 && e.StatusCode < 500:
+		return exitValidation
+	case e.StatusCode == 0 && e.Type == "auth":
+		return exitAuth
+	case e.StatusCode == 0 && e.Type == "invalid_request":
+		return exitValidation
+	}
+
+	return exitFailure
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	var ue *usageError
+	if errors.As(err, &ue) {
+		return exitUsage
+	}
+
+	var ae *apiError
+	if errors.As(wrapError(err), &ae) {
+		return ae.ExitCode()
+	}
+
+	return exitFailure
+}
+
+// printError prints err in red, followed by the documentation link of Meilisearch errors.
+func printError(err error) {
+	color.Red(err.Error())
+
+	var ae *apiError
+	if errors.As(err, &ae) && len(ae.Link) != 0 {
+		color.Yellow("see %s", ae.Link)
+	}
+}
+
+// wrapErrors makes the commands of the tree return apiError instead of
+// the verbose errors of the Meilisearch client.
+func wrapErrors(cmd *cobra.Command) {
+	if run := cmd.RunE; run != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			return wrapError(run(cmd, args))
+		}
+	}
+
+	for _, c := range cmd.Commands() {
+		wrapErrors(c)
+	}
+}
+
+// usageErrors makes the flag and argument errors of the commands of the tree
+// usage errors, cobra returns them as plain errors.
+func usageErrors(cmd *cobra.Command) {
+	args := cmd.Args
+	if !cmd.HasParent() {
+		cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+			return usageErrorf("%s", err.Error())
+		})
+
+		// cobra reports unknown commands itself when the root has no Args,
+		// as plain errors and without a way to change them
+		if args == nil {
+			args = cobra.NoArgs
+			if !cmd.Runnable() {
+				cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+					return cmd.Help()
+				}
+			}
+		}
+	}
+
+	if args != nil {
+		cmd.Args = func(cmd *cobra.Command, a []string) error {
+			if err := args(cmd, a); err != nil {
+				return usageErrorf("%s", err.Error())
+			}
+			return nil
+		}
+	}
+
+	for _, c := range cmd.Commands() {
+		usageErrors(c)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func newMeilisearchError(status int, code string) *meilisearch.Error {
+	e := &meilisearch.Error{StatusCode: status}
+	e.MeilisearchApiError.Code = code
+	e.MeilisearchApiError.Message = "message of " + code
+	e.MeilisearchApiError.Link = "https://docs.meilisearch.com/errors#" + code
+	return e.WithErrCode(meilisearch.MeilisearchApiError)
+}
+
+func TestExitCode(t *testing.T) {
+	network := (&meilisearch.Error{}).WithErrCode(meilisearch.MeilisearchCommunicationError, errors.New("connection refused"))
+
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, exitOK},
+		{errors.New("boom"), exitFailure},
+		{usageErrorf("index uid is require"), exitUsage},
+		{newMeilisearchError(401, "missing_authorization_header"), exitAuth},
+		{newMeilisearchError(403, "invalid_api_key"), exitAuth},
+		{newMeilisearchError(404, "index_not_found"), exitNotFound},
+		{newMeilisearchError(400, "invalid_search_filter"), exitValidation},
+		{newMeilisearchError(500, "internal"), exitFailure},
+		{network, exitNetwork},
+		{fmt.Errorf("line 2: %w", wrapError(newMeilisearchError(404, "index_not_found"))), exitNotFound},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.code, exitCode(tt.err), "%v", tt.err)
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	require.NoError(t, wrapError(nil))
+
+	err := wrapError(newMeilisearchError(404, "index_not_found"))
+	require.EqualError(t, err, "message of index_not_found (index_not_found, status 404)")
+
+	var ae *apiError
+	require.ErrorAs(t, err, &ae)
+	require.Equal(t, "https://docs.meilisearch.com/errors#index_not_found", ae.Link)
+	require.Same(t, ae, wrapError(ae))
+
+	network := (&meilisearch.Error{}).WithErrCode(meilisearch.MeilisearchCommunicationError, errors.New("connection refused"))
+	require.EqualError(t, wrapError(network), "failed to reach Meilisearch: connection refused")
+
+	plain := errors.New("boom")
+	require.Equal(t, plain, wrapError(plain))
+}
+
+func TestUsageErrors(t *testing.T) {
+	root := &cobra.Command{Use: "root", SilenceErrors: true, SilenceUsage: true}
+	root.AddCommand(&cobra.Command{
+		Use:  "one",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, _ []string) error { return nil },
+	})
+	usageErrors(root)
+
+	for _, args := range [][]string{{"one", "--unknown"}, {"one", "a", "b"}, {"unknown"}} {
+		root.SetArgs(args)
+		err := root.Execute()
+		require.Error(t, err, args)
+		require.Equal(t, exitUsage, exitCode(err), args)
+	}
+
+	root.SetArgs([]string{"one", "a"})
+	require.NoError(t, root.Execute())
+}
@@ -0,0 +1,1179 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeMasterKey = "masterKey"
+
+// fakeNow is the time of everything the fake Meilisearch creates, so the
+// outputs do not change between runs.
+var fakeNow = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+const fakeDefaultSettings = `{
+	"rankingRules": ["words", "typo", "proximity", "attribute", "sort", "exactness"],
+	"distinctAttribute": null,
+	"searchableAttributes": ["*"],
+	"displayedAttributes": ["*"],
+	"stopWords": [],
+	"synonyms": {},
+	"filterableAttributes": [],
+	"sortableAttributes": [],
+	"typoTolerance": {
+		"enabled": true,
+		"minWordSizeForTypos": {"oneTypo": 5, "twoTypos": 9},
+		"disableOnWords": [],
+		"disableOnAttributes": []
+	},
+	"pagination": {"maxTotalHits": 1000},
+	"faceting": {"maxValuesPerFacet": 100},
+	"searchCutoffMs": null,
+	"embedders": {},
+	"proximityPrecision": "byWord",
+	"separatorTokens": [],
+	"nonSeparatorTokens": [],
+	"dictionary": [],
+	"localizedAttributes": null
+}`
+
+// fakeMeilisearch is an in-process stand-in of the Meilisearch endpoints used
+// by the commands. Tasks are processed as soon as they are enqueued, unless
+// the fake is paused.
+type fakeMeilisearch struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	indexes map[string]*fakeIndex
+	keys    []*meilisearch.Key
+	tasks   []*meilisearch.Task
+	paused  bool
+
+	// createdKeys numbers the uids of created keys, which are not reused after a deletion
+	createdKeys int
+
+	// bodies are the last request bodies by method and path, e.g. "POST /indexes/movies/search"
+	bodies map[string][]byte
+}
+
+type fakeIndex struct {
+	UID        string    `json:"uid"`
+	PrimaryKey string    `json:"primaryKey,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+
+	settings map[string]interface{}
+
+	// documents are the added documents as sent, one JSON object each, csv
+	// rows are converted to objects of strings like Meilisearch does
+	documents []string
+}
+
+type fakeError struct {
+	status int
+	code   string
+	typ    string
+	msg    string
+}
+
+func newFakeMeilisearch(t *testing.T) *fakeMeilisearch {
+	f := &fakeMeilisearch{indexes: make(map[string]*fakeIndex), bodies: make(map[string][]byte)}
+
+	f.addKey(&meilisearch.Key{
+		Name:        "Default Search API Key",
+		Description: "Use it to search from the frontend",
+		UID:         "10000000-0000-4000-8000-000000000001",
+		Actions:     []string{"search"},
+		Indexes:     []string{"*"},
+	})
+	f.addKey(&meilisearch.Key{
+		Name:        "Default Admin API Key",
+		Description: "Use it for anything that is not a search operation. Caution! Do not expose it on a public frontend",
+		UID:         "10000000-0000-4000-8000-000000000002",
+		Actions:     []string{"*"},
+		Indexes:     []string{"*"},
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", f.health)
+	mux.HandleFunc("GET /version", f.auth(f.version))
+	mux.HandleFunc("GET /stats", f.auth(f.stats))
+	mux.HandleFunc("POST /dumps", f.auth(f.createDump))
+
+	mux.HandleFunc("GET /indexes", f.auth(f.listIndexes))
+	mux.HandleFunc("POST /indexes", f.auth(f.createIndex))
+	mux.HandleFunc("GET /indexes/{uid}", f.auth(f.getIndex))
+	mux.HandleFunc("DELETE /indexes/{uid}", f.auth(f.deleteIndex))
+	mux.HandleFunc("POST /swap-indexes", f.auth(f.swapIndexes))
+
+	mux.HandleFunc("GET /indexes/{uid}/settings", f.auth(f.getSettings))
+	mux.HandleFunc("PATCH /indexes/{uid}/settings", f.auth(f.updateSettings))
+	mux.HandleFunc("DELETE /indexes/{uid}/settings", f.auth(f.resetSettings))
+	mux.HandleFunc("GET /indexes/{uid}/settings/{setting}", f.auth(f.getSetting))
+	mux.HandleFunc("PUT /indexes/{uid}/settings/{setting}", f.auth(f.updateSetting))
+	mux.HandleFunc("PATCH /indexes/{uid}/settings/{setting}", f.auth(f.updateSetting))
+	mux.HandleFunc("DELETE /indexes/{uid}/settings/{setting}", f.auth(f.resetSetting))
+
+	mux.HandleFunc("POST /indexes/{uid}/documents", f.auth(f.addDocuments))
+	mux.HandleFunc("PUT /indexes/{uid}/documents", f.auth(f.addDocuments))
+	mux.HandleFunc("GET /indexes/{uid}/documents", f.auth(f.getDocuments))
+	mux.HandleFunc("POST /indexes/{uid}/documents/fetch", f.auth(f.getDocuments))
+	mux.HandleFunc("GET /indexes/{uid}/documents/{id}", f.auth(f.getDocument))
+	mux.HandleFunc("DELETE /indexes/{uid}/documents/{id}", f.auth(f.deleteDocuments))
+	mux.HandleFunc("POST /indexes/{uid}/documents/delete-batch", f.auth(f.deleteDocuments))
+	mux.HandleFunc("DELETE /indexes/{uid}/documents", f.auth(f.deleteDocuments))
+
+	mux.HandleFunc("POST /indexes/{uid}/search", f.auth(f.search))
+	mux.HandleFunc("POST /indexes/{uid}/facet-search", f.auth(f.facetSearch))
+	mux.HandleFunc("POST /multi-search", f.auth(f.multiSearch))
+
+	mux.HandleFunc("GET /keys", f.auth(f.listKeys))
+	mux.HandleFunc("POST /keys", f.auth(f.createKey))
+	mux.HandleFunc("GET /keys/{key}", f.auth(f.getKey))
+	mux.HandleFunc("PATCH /keys/{key}", f.auth(f.updateKey))
+	mux.HandleFunc("DELETE /keys/{key}", f.auth(f.deleteKey))
+
+	mux.HandleFunc("GET /tasks", f.auth(f.listTasks))
+	mux.HandleFunc("GET /tasks/{uid}", f.auth(f.getTask))
+	mux.HandleFunc("POST /tasks/cancel", f.auth(f.cancelTasks))
+	mux.HandleFunc("DELETE /tasks", f.auth(f.deleteTasks))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeError(w, &fakeError{http.StatusNotFound, "not_found", "invalid_request", "Not found."})
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+// addIndex creates an index with a succeeded indexCreation task.
+func (f *fakeMeilisearch) addIndex(uid, primaryKey string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.enqueue(uid, meilisearch.TaskTypeIndexCreation, meilisearch.Details{PrimaryKey: primaryKey}, func() *fakeError {
+		return f.createIndexLocked(uid, primaryKey)
+	})
+}
+
+func (f *fakeMeilisearch) addKey(k *meilisearch.Key) {
+	k.Key = fakeKeyOf(k.UID)
+	k.CreatedAt = fakeNow
+	k.UpdatedAt = fakeNow
+	f.keys = append(f.keys, k)
+}
+
+// pause leaves the next tasks enqueued, e.g. to cancel them.
+func (f *fakeMeilisearch) pause() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.paused = true
+}
+
+// fakeKeyOf derives the key from its uid like Meilisearch does, with a HMAC of the master key.
+func fakeKeyOf(uid string) string {
+	mac := hmac.New(sha256.New, []byte(fakeMasterKey))
+	mac.Write([]byte(uid))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (f *fakeMeilisearch) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if len(header) == 0 {
+			writeFakeError(w, &fakeError{http.StatusUnauthorized, "missing_authorization_header", "auth",
+				"The Authorization header is missing. It must use the bearer authorization method."})
+			return
+		}
+
+		if strings.TrimPrefix(header, "Bearer ") != fakeMasterKey {
+			writeFakeError(w, &fakeError{http.StatusForbidden, "invalid_api_key", "auth",
+				"The provided API key is invalid."})
+			return
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		f.bodies[r.Method+" "+r.URL.Path] = body
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		next(w, r)
+	}
+}
+
+// body returns the decoded body of the last request with the method and path.
+func (f *fakeMeilisearch) body(t *testing.T, method, path string) interface{} {
+	t.Helper()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, ok := f.bodies[method+" "+path]
+	require.True(t, ok, "no %s %s request", method, path)
+
+	var v interface{}
+	require.NoError(t, json.Unmarshal(b, &v))
+	return v
+}
+
+func (f *fakeMeilisearch) health(w http.ResponseWriter, _ *http.Request) {
+	writeFakeJSON(w, http.StatusOK, map[string]string{"status": "available"})
+}
+
+func (f *fakeMeilisearch) version(w http.ResponseWriter, _ *http.Request) {
+	writeFakeJSON(w, http.StatusOK, map[string]string{
+		"commitSha":  "b5a1d0e0c0a2e53fbbe7c5b1b6e0c0a2e53fbbe7",
+		"commitDate": "2024-05-27T08:30:59Z",
+		"pkgVersion": "1.8.1",
+	})
+}
+
+func (f *fakeMeilisearch) stats(w http.ResponseWriter, _ *http.Request) {
+	indexes := make(map[string]interface{}, len(f.indexes))
+	for uid := range f.indexes {
+		indexes[uid] = map[string]interface{}{
+			"numberOfDocuments": 0,
+			"isIndexing":        false,
+			"fieldDistribution": map[string]int{},
+		}
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"databaseSize": 1 << 20,
+		"lastUpdate":   fakeNow,
+		"indexes":      indexes,
+	})
+}
+
+func (f *fakeMeilisearch) createDump(w http.ResponseWriter, _ *http.Request) {
+	info := f.enqueue("", meilisearch.TaskTypeDumpCreation, meilisearch.Details{DumpUid: "20240102-030405000"},
+		func() *fakeError { return nil })
+
+	writeFakeJSON(w, http.StatusAccepted, info)
+}
+
+func (f *fakeMeilisearch) listIndexes(w http.ResponseWriter, r *http.Request) {
+	uids := make([]string, 0, len(f.indexes))
+	for uid := range f.indexes {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	offset, limit := fakePage(r, 20)
+	results := make([]*fakeIndex, 0)
+	for i := offset; i < len(uids) && i < offset+limit; i++ {
+		results = append(results, f.indexes[uids[i]])
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"results": results,
+		"offset":  offset,
+		"limit":   limit,
+		"total":   len(uids),
+	})
+}
+
+func (f *fakeMeilisearch) createIndex(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		UID        string `json:"uid"`
+		PrimaryKey string `json:"primaryKey"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeFakeError(w, &fakeError{http.StatusBadRequest, "bad_request", "invalid_request", err.Error()})
+		return
+	}
+
+	info := f.enqueue(body.UID, meilisearch.TaskTypeIndexCreation, meilisearch.Details{PrimaryKey: body.PrimaryKey},
+		func() *fakeError { return f.createIndexLocked(body.UID, body.PrimaryKey) })
+
+	writeFakeJSON(w, http.StatusAccepted, info)
+}
+
+func (f *fakeMeilisearch) createIndexLocked(uid, primaryKey string) *fakeError {
+	if _, ok := f.indexes[uid]; ok {
+		return &fakeError{http.StatusConflict, "index_already_exists", "invalid_request",
+			fmt.Sprintf("Index `%s` already exists.", uid)}
+	}
+
+	var settings map[string]interface{}
+	_ = json.Unmarshal([]byte(fakeDefaultSettings), &settings)
+
+	f.indexes[uid] = &fakeIndex{
+		UID:        uid,
+		PrimaryKey: primaryKey,
+		CreatedAt:  fakeNow,
+		UpdatedAt:  fakeNow,
+		settings:   settings,
+	}
+	return nil
+}
+
+func (f *fakeMeilisearch) getIndex(w http.ResponseWriter, r *http.Request) {
+	idx, err := f.index(r.PathValue("uid"))
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	writeFakeJSON(w, http.StatusOK, idx)
+}
+
+func (f *fakeMeilisearch) deleteIndex(w http.ResponseWriter, r *http.Request) {
+	uid := r.PathValue("uid")
+	info := f.enqueue(uid, meilisearch.TaskTypeIndexDeletion, meilisearch.Details{}, func() *fakeError {
+		if _, err := f.index(uid); err != nil {
+			return err
+		}
+
+		delete(f.indexes, uid)
+		return nil
+	})
+
+	writeFakeJSON(w, http.StatusAccepted, info)
+}
+
+func (f *fakeMeilisearch) swapIndexes(w http.ResponseWriter, r *http.Request) {
+	var swaps []meilisearch.SwapIndexesParams
+	if err := json.NewDecoder(r.Body).Decode(&swaps); err != nil {
+		writeFakeError(w, &fakeError{http.StatusBadRequest, "bad_request", "invalid_request", err.Error()})
+		return
+	}
+
+	info := f.enqueue("", meilisearch.TaskTypeIndexSwap, meilisearch.Details{Swaps: swaps}, func() *fakeError {
+		for _, s := range swaps {
+			for _, uid := range s.Indexes {
+				if _, err := f.index(uid); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, s := range swaps {
+			a, b := f.indexes[s.Indexes[0]], f.indexes[s.Indexes[1]]
+			a.PrimaryKey, b.PrimaryKey = b.PrimaryKey, a.PrimaryKey
+			a.settings, b.settings = b.settings, a.settings
+		}
+		return nil
+	})
+
+	writeFakeJSON(w, http.StatusAccepted, info)
+}
+
+func (f *fakeMeilisearch) getSettings(w http.ResponseWriter, r *http.Request) {
+	idx, err := f.index(r.PathValue("uid"))
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	writeFakeJSON(w, http.StatusOK, idx.settings)
+}
+
+func (f *fakeMeilisearch) updateSettings(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeFakeError(w, &fakeError{http.StatusBadRequest, "bad_request", "invalid_request", err.Error()})
+		return
+	}
+
+	var defaults map[string]interface{}
+	_ = json.Unmarshal([]byte(fakeDefaultSettings), &defaults)
+	for name := range body {
+		if _, ok := defaults[name]; !ok {
+			writeFakeError(w, &fakeError{http.StatusBadRequest, "bad_request", "invalid_request",
+				fmt.Sprintf("Unknown field `%s`", name)})
+			return
+		}
+	}
+
+	f.writeSettingsTask(w, r.PathValue("uid"), func(idx *fakeIndex) {
+		for name, v := range body {
+			idx.setSetting(name, v, true)
+		}
+	})
+}
+
+func (f *fakeMeilisearch) resetSettings(w http.ResponseWriter, r *http.Request) {
+	f.writeSettingsTask(w, r.PathValue("uid"), func(idx *fakeIndex) {
+		_ = json.Unmarshal([]byte(fakeDefaultSettings), &idx.settings)
+	})
+}
+
+func (f *fakeMeilisearch) getSetting(w http.ResponseWriter, r *http.Request) {
+	idx, err := f.index(r.PathValue("uid"))
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	v, ok := idx.settings[fakeSettingName(r.PathValue("setting"))]
+	if !ok {
+		writeFakeError(w, &fakeError{http.StatusNotFound, "not_found", "invalid_request", "Not found."})
+		return
+	}
+
+	writeFakeJSON(w, http.StatusOK, v)
+}
+
+func (f *fakeMeilisearch) updateSetting(w http.ResponseWriter, r *http.Request) {
+	var body interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeFakeError(w, &fakeError{http.StatusBadRequest, "bad_request", "invalid_request", err.Error()})
+		return
+	}
+
+	name := fakeSettingName(r.PathValue("setting"))
+	f.writeSettingsTask(w, r.PathValue("uid"), func(idx *fakeIndex) {
+		idx.setSetting(name, body, r.Method == http.MethodPatch)
+	})
+}
+
+func (f *fakeMeilisearch) resetSetting(w http.ResponseWriter, r *http.Request) {
+	name := fakeSettingName(r.PathValue("setting"))
+	f.writeSettingsTask(w, r.PathValue("uid"), func(idx *fakeIndex) {
+		idx.setSetting(name, nil, false)
+	})
+}
+
+// writeSettingsTask enqueues a settingsUpdate task applying update, which
+// creates the index when it does not exist like Meilisearch does.
+func (f *fakeMeilisearch) writeSettingsTask(w http.ResponseWriter, uid string, update func(idx *fakeIndex)) {
+	info := f.enqueue(uid, meilisearch.TaskTypeSettingsUpdate, meilisearch.Details{}, func() *fakeError {
+		if _, ok := f.indexes[uid]; !ok {
+			_ = f.createIndexLocked(uid, "")
+		}
+
+		update(f.indexes[uid])
+		return nil
+	})
+
+	writeFakeJSON(w, http.StatusAccepted, info)
+}
+
+// setSetting sets a setting, null resets it to its default and merge updates
+// the fields of object settings instead of replacing them.
+func (idx *fakeIndex) setSetting(name string, v interface{}, merge bool) {
+	if v == nil {
+		var defaults map[string]interface{}
+		_ = json.Unmarshal([]byte(fakeDefaultSettings), &defaults)
+		idx.settings[name] = defaults[name]
+		return
+	}
+
+	current, ok := idx.settings[name].(map[string]interface{})
+	fields, isObject := v.(map[string]interface{})
+	if merge && ok && isObject && name != "synonyms" {
+		for k, fv := range fields {
+			current[k] = fv
+		}
+		return
+	}
+
+	idx.settings[name] = v
+}
+
+// fakeSettingName turns the setting of a route into its field, e.g. ranking-rules into rankingRules.
+func fakeSettingName(route string) string {
+	parts := strings.Split(route, "-")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+
+	return strings.Join(parts, "")
+}
+
+// addDocuments stores the documents of the json, ndjson or csv body, replacing
+// the documents with the same id, or updating them on PUT. The index is created
+// when it does not exist like Meilisearch does.
+func (f *fakeMeilisearch) addDocuments(w http.ResponseWriter, r *http.Request) {
+	uid := r.PathValue("uid")
+	body, _ := io.ReadAll(r.Body)
+
+	docs := make([]string, 0)
+	switch r.Header.Get("Content-Type") {
+	case "application/x-ndjson":
+		for _, line := range strings.Split(string(body), "\n") {
+			if len(strings.TrimSpace(line)) != 0 {
+				docs = append(docs, line)
+			}
+		}
+	case "text/csv":
+		rows, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+		if err != nil || len(rows) == 0 {
+			writeFakeError(w, &fakeError{http.StatusBadRequest, "malformed_payload", "invalid_request", "invalid csv"})
+			return
+		}
+		for _, row := range rows[1:] {
+			doc := make(map[string]string, len(row))
+			for i, v := range row {
+				doc[rows[0][i]] = v
+			}
+			b, _ := json.Marshal(doc)
+			docs = append(docs, string(b))
+		}
+	default:
+		raw := make([]json.RawMessage, 0)
+		if err := json.Unmarshal(body, &raw); err != nil {
+			writeFakeError(w, &fakeError{http.StatusBadRequest, "malformed_payload", "invalid_request", err.Error()})
+			return
+		}
+		for _, doc := range raw {
+			docs = append(docs, string(doc))
+		}
+	}
+
+	primaryKey := r.URL.Query().Get("primaryKey")
+	info := f.enqueue(uid, meilisearch.TaskTypeDocumentAdditionOrUpdate,
+		meilisearch.Details{ReceivedDocuments: int64(len(docs)), IndexedDocuments: int64(len(docs))},
+		func() *fakeError {
+			if _, ok := f.indexes[uid]; !ok {
+				_ = f.createIndexLocked(uid, primaryKey)
+			}
+
+			idx := f.indexes[uid]
+			if len(idx.PrimaryKey) == 0 && len(docs) != 0 {
+				idx.PrimaryKey = fakePrimaryKeyOf(docs[0])
+			}
+			for _, doc := range docs {
+				idx.saveDocument(doc, r.Method == http.MethodPut)
+			}
+			return nil
+		})
+
+	writeFakeJSON(w, http.StatusAccepted, info)
+}
+
+// saveDocument replaces or, with merge, updates the document with the id of
+// doc, or appends doc.
+func (idx *fakeIndex) saveDocument(doc string, merge bool) {
+	id := idx.documentID(doc)
+	for i, current := range idx.documents {
+		if idx.documentID(current) != id {
+			continue
+		}
+
+		if merge {
+			fields := fakeDocumentFields(current)
+			for k, v := range fakeDocumentFields(doc) {
+				fields[k] = v
+			}
+			b, _ := json.Marshal(fields)
+			doc = string(b)
+		}
+		idx.documents[i] = doc
+		return
+	}
+
+	idx.documents = append(idx.documents, doc)
+}
+
+func (idx *fakeIndex) documentID(doc string) string {
+	return fmt.Sprint(fakeDocumentFields(doc)[idx.PrimaryKey])
+}
+
+// fakeDocumentFields decodes doc keeping its numbers as written.
+func fakeDocumentFields(doc string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	d := json.NewDecoder(strings.NewReader(doc))
+	d.UseNumber()
+	_ = d.Decode(&fields)
+
+	return fields
+}
+
+// fakePrimaryKeyOf infers the primary key from the first attribute ending with id.
+func fakePrimaryKeyOf(doc string) string {
+	fields := fakeDocumentFields(doc)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if strings.HasSuffix(strings.ToLower(name), "id") {
+			return name
+		}
+	}
+	return ""
+}
+
+// fakeSelectFields keeps the fields of doc, all of them when fields is empty or *.
+func fakeSelectFields(doc string, fields []string) json.RawMessage {
+	if len(fields) == 0 || slices.Contains(fields, "*") {
+		return json.RawMessage(doc)
+	}
+
+	all := fakeDocumentFields(doc)
+	selected := make(map[string]interface{})
+	for _, field := range fields {
+		if v, ok := all[field]; ok {
+			selected[field] = v
+		}
+	}
+
+	b, _ := json.Marshal(selected)
+	return b
+}
+
+func (f *fakeMeilisearch) getDocument(w http.ResponseWriter, r *http.Request) {
+	idx, err := f.index(r.PathValue("uid"))
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	var fields []string
+	if v := r.URL.Query().Get("fields"); len(v) != 0 {
+		fields = strings.Split(v, ",")
+	}
+
+	id := r.PathValue("id")
+	for _, doc := range idx.documents {
+		if idx.documentID(doc) == id {
+			writeFakeJSON(w, http.StatusOK, fakeSelectFields(doc, fields))
+			return
+		}
+	}
+
+	writeFakeError(w, &fakeError{http.StatusNotFound, "document_not_found", "invalid_request",
+		fmt.Sprintf("Document `%s` not found.", id)})
+}
+
+// deleteDocuments deletes one document, the documents of a batch of ids or
+// all documents, depending on the route.
+func (f *fakeMeilisearch) deleteDocuments(w http.ResponseWriter, r *http.Request) {
+	uid := r.PathValue("uid")
+	idx, err := f.index(uid)
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	var ids []string
+	switch {
+	case len(r.PathValue("id")) != 0:
+		ids = []string{r.PathValue("id")}
+	case r.Method == http.MethodPost:
+		raw := make([]interface{}, 0)
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			writeFakeError(w, &fakeError{http.StatusBadRequest, "bad_request", "invalid_request", err.Error()})
+			return
+		}
+		for _, id := range raw {
+			ids = append(ids, fmt.Sprint(id))
+		}
+	}
+
+	info := f.enqueue(uid, meilisearch.TaskTypeDocumentDeletion, meilisearch.Details{ProvidedIds: int64(len(ids))},
+		func() *fakeError {
+			kept := make([]string, 0, len(idx.documents))
+			for _, doc := range idx.documents {
+				// no ids deletes all documents
+				if ids != nil && !slices.Contains(ids, idx.documentID(doc)) {
+					kept = append(kept, doc)
+				}
+			}
+			idx.documents = kept
+			return nil
+		})
+
+	writeFakeJSON(w, http.StatusAccepted, info)
+}
+
+// getDocuments pages through the documents as they were added, the filter of
+// POST /documents/fetch is recorded but not applied.
+func (f *fakeMeilisearch) getDocuments(w http.ResponseWriter, r *http.Request) {
+	idx, err := f.index(r.PathValue("uid"))
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	q := struct {
+		Offset int      `json:"offset"`
+		Limit  int      `json:"limit"`
+		Fields []string `json:"fields"`
+	}{Limit: 20}
+	if r.Method == http.MethodPost {
+		_ = json.NewDecoder(r.Body).Decode(&q)
+	} else {
+		if v, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil {
+			q.Offset = v
+		}
+		if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
+			q.Limit = v
+		}
+		if v := r.URL.Query().Get("fields"); len(v) != 0 {
+			q.Fields = strings.Split(v, ",")
+		}
+	}
+
+	results := make([]json.RawMessage, 0)
+	for i := q.Offset; i < len(idx.documents) && i < q.Offset+q.Limit; i++ {
+		results = append(results, fakeSelectFields(idx.documents[i], q.Fields))
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"results": results,
+		"offset":  q.Offset,
+		"limit":   q.Limit,
+		"total":   len(idx.documents),
+	})
+}
+
+// search answers every search without hits, the commands are tested on the request they send.
+func (f *fakeMeilisearch) search(w http.ResponseWriter, r *http.Request) {
+	if _, err := f.index(r.PathValue("uid")); err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	writeFakeJSON(w, http.StatusOK, fakeSearchResult(r.PathValue("uid")))
+}
+
+func (f *fakeMeilisearch) facetSearch(w http.ResponseWriter, r *http.Request) {
+	if _, err := f.index(r.PathValue("uid")); err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"facetHits":        []map[string]interface{}{{"value": "action", "count": 2}},
+		"facetQuery":       nil,
+		"processingTimeMs": 0,
+	})
+}
+
+func (f *fakeMeilisearch) multiSearch(w http.ResponseWriter, r *http.Request) {
+	body := new(meilisearch.MultiSearchRequest)
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		writeFakeError(w, &fakeError{http.StatusBadRequest, "bad_request", "invalid_request", err.Error()})
+		return
+	}
+
+	results := make([]map[string]interface{}, 0, len(body.Queries))
+	for _, q := range body.Queries {
+		if _, err := f.index(q.IndexUID); err != nil {
+			writeFakeError(w, err)
+			return
+		}
+		results = append(results, fakeSearchResult(q.IndexUID))
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+}
+
+func fakeSearchResult(uid string) map[string]interface{} {
+	return map[string]interface{}{
+		"indexUid":           uid,
+		"hits":               []interface{}{},
+		"query":              "",
+		"processingTimeMs":   0,
+		"limit":              20,
+		"offset":             0,
+		"estimatedTotalHits": 0,
+	}
+}
+
+func (f *fakeMeilisearch) listKeys(w http.ResponseWriter, r *http.Request) {
+	offset, limit := fakePage(r, 20)
+	results := make([]map[string]interface{}, 0)
+	for i := offset; i < len(f.keys) && i < offset+limit; i++ {
+		results = append(results, fakeKeyJSON(f.keys[i]))
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"results": results,
+		"offset":  offset,
+		"limit":   limit,
+		"total":   len(f.keys),
+	})
+}
+
+func (f *fakeMeilisearch) createKey(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		UID         string   `json:"uid"`
+		Actions     []string `json:"actions"`
+		Indexes     []string `json:"indexes"`
+		ExpiresAt   *string  `json:"expiresAt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeFakeError(w, &fakeError{http.StatusBadRequest, "bad_request", "invalid_request", err.Error()})
+		return
+	}
+
+	if len(body.Actions) == 0 {
+		writeFakeError(w, &fakeError{http.StatusBadRequest, "missing_api_key_actions", "invalid_request",
+			"Missing field `actions`"})
+		return
+	}
+
+	k := &meilisearch.Key{
+		Name:        body.Name,
+		Description: body.Description,
+		UID:         body.UID,
+		Actions:     body.Actions,
+		Indexes:     body.Indexes,
+	}
+
+	if body.ExpiresAt != nil {
+		t, err := time.Parse(time.RFC3339, *body.ExpiresAt)
+		if err != nil {
+			writeFakeError(w, &fakeError{http.StatusBadRequest, "invalid_api_key_expires_at", "invalid_request",
+				fmt.Sprintf("Invalid value type at `.expiresAt`: `%s`", *body.ExpiresAt)})
+			return
+		}
+		k.ExpiresAt = t
+	}
+
+	if len(k.UID) == 0 {
+		f.createdKeys++
+		k.UID = fmt.Sprintf("20000000-0000-4000-8000-%012d", f.createdKeys)
+	}
+
+	if _, err := f.key(k.UID); err == nil {
+		writeFakeError(w, &fakeError{http.StatusConflict, "api_key_already_exists", "invalid_request",
+			fmt.Sprintf("`uid` field value `%s` is already an existing API key.", k.UID)})
+		return
+	}
+
+	f.addKey(k)
+	writeFakeJSON(w, http.StatusCreated, fakeKeyJSON(k))
+}
+
+func (f *fakeMeilisearch) getKey(w http.ResponseWriter, r *http.Request) {
+	k, err := f.key(r.PathValue("key"))
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	writeFakeJSON(w, http.StatusOK, fakeKeyJSON(k))
+}
+
+func (f *fakeMeilisearch) updateKey(w http.ResponseWriter, r *http.Request) {
+	k, err := f.key(r.PathValue("key"))
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	var body struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeFakeError(w, &fakeError{http.StatusBadRequest, "bad_request", "invalid_request", err.Error()})
+		return
+	}
+
+	if body.Name != nil {
+		k.Name = *body.Name
+	}
+	if body.Description != nil {
+		k.Description = *body.Description
+	}
+
+	writeFakeJSON(w, http.StatusOK, fakeKeyJSON(k))
+}
+
+func (f *fakeMeilisearch) deleteKey(w http.ResponseWriter, r *http.Request) {
+	k, err := f.key(r.PathValue("key"))
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	f.keys = slices.DeleteFunc(f.keys, func(v *meilisearch.Key) bool { return v == k })
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// fakeKeyJSON is the key as Meilisearch returns it, with a null expiresAt when it does not expire.
+func fakeKeyJSON(k *meilisearch.Key) map[string]interface{} {
+	v := map[string]interface{}{
+		"name":        k.Name,
+		"description": k.Description,
+		"key":         k.Key,
+		"uid":         k.UID,
+		"actions":     k.Actions,
+		"indexes":     k.Indexes,
+		"expiresAt":   nil,
+		"createdAt":   k.CreatedAt,
+		"updatedAt":   k.UpdatedAt,
+	}
+	if !k.ExpiresAt.IsZero() {
+		v["expiresAt"] = k.ExpiresAt
+	}
+
+	return v
+}
+
+func (f *fakeMeilisearch) listTasks(w http.ResponseWriter, r *http.Request) {
+	matching, err := f.matchTasks(r)
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	limit := 20
+	if v := r.URL.Query().Get("limit"); len(v) != 0 {
+		limit, _ = strconv.Atoi(v)
+	}
+
+	start := 0
+	if v := r.URL.Query().Get("from"); len(v) != 0 {
+		from, _ := strconv.ParseInt(v, 10, 64)
+		for start < len(matching) && matching[start].UID > from {
+			start++
+		}
+	}
+
+	end := min(start+limit, len(matching))
+	results := matching[start:end]
+
+	res := map[string]interface{}{
+		"results": results,
+		"total":   len(matching),
+		"limit":   limit,
+		"from":    nil,
+		"next":    nil,
+	}
+	if len(results) != 0 {
+		res["from"] = results[0].UID
+	}
+	if end < len(matching) {
+		res["next"] = matching[end].UID
+	}
+
+	writeFakeJSON(w, http.StatusOK, res)
+}
+
+func (f *fakeMeilisearch) getTask(w http.ResponseWriter, r *http.Request) {
+	uid, _ := strconv.ParseInt(r.PathValue("uid"), 10, 64)
+	for _, t := range f.tasks {
+		if t.UID == uid {
+			writeFakeJSON(w, http.StatusOK, t)
+			return
+		}
+	}
+
+	writeFakeError(w, &fakeError{http.StatusNotFound, "task_not_found", "invalid_request",
+		fmt.Sprintf("Task `%d` not found.", uid)})
+}
+
+func (f *fakeMeilisearch) cancelTasks(w http.ResponseWriter, r *http.Request) {
+	matching, err := f.matchTasks(r)
+	if err == nil && len(r.URL.RawQuery) == 0 {
+		err = &fakeError{http.StatusBadRequest, "missing_task_filters", "invalid_request",
+			"Query parameters to filter the tasks to cancel are missing."}
+	}
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	details := meilisearch.Details{MatchedTasks: int64(len(matching)), OriginalFilter: "?" + r.URL.RawQuery}
+	info := f.process(f.newTask("", meilisearch.TaskTypeTaskCancelation, details), func(t *meilisearch.Task) *fakeError {
+		for _, m := range matching {
+			if m.Status == meilisearch.TaskStatusEnqueued || m.Status == meilisearch.TaskStatusProcessing {
+				m.Status = meilisearch.TaskStatusCanceled
+				m.CanceledBy = t.UID
+				m.FinishedAt = fakeNow
+				t.Details.CanceledTasks++
+			}
+		}
+		return nil
+	})
+
+	writeFakeJSON(w, http.StatusOK, info)
+}
+
+func (f *fakeMeilisearch) deleteTasks(w http.ResponseWriter, r *http.Request) {
+	matching, err := f.matchTasks(r)
+	if err == nil && len(r.URL.RawQuery) == 0 {
+		err = &fakeError{http.StatusBadRequest, "missing_task_filters", "invalid_request",
+			"Query parameters to filter the tasks to delete are missing."}
+	}
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+
+	details := meilisearch.Details{MatchedTasks: int64(len(matching)), OriginalFilter: "?" + r.URL.RawQuery}
+	info := f.process(f.newTask("", meilisearch.TaskTypeTaskDeletion, details), func(t *meilisearch.Task) *fakeError {
+		f.tasks = slices.DeleteFunc(f.tasks, func(v *meilisearch.Task) bool {
+			finished := v.Status != meilisearch.TaskStatusEnqueued && v.Status != meilisearch.TaskStatusProcessing
+			if finished && slices.Contains(matching, v) {
+				t.Details.DeletedTasks++
+				return true
+			}
+			return false
+		})
+		return nil
+	})
+
+	writeFakeJSON(w, http.StatusOK, info)
+}
+
+// matchTasks returns the tasks matching the filters of the query, newest first.
+func (f *fakeMeilisearch) matchTasks(r *http.Request) ([]*meilisearch.Task, *fakeError) {
+	q := r.URL.Query()
+	list := func(name string) []string {
+		if v := q.Get(name); len(v) != 0 {
+			return strings.Split(v, ",")
+		}
+		return nil
+	}
+
+	uids, statuses, types, indexUIDs := list("uids"), list("statuses"), list("types"), list("indexUids")
+	for _, s := range statuses {
+		if !slices.Contains(taskStatuses, s) {
+			return nil, &fakeError{http.StatusBadRequest, "invalid_task_statuses", "invalid_request",
+				fmt.Sprintf("Invalid value in parameter `statuses`: `%s` is not a valid task status.", s)}
+		}
+	}
+
+	matching := make([]*meilisearch.Task, 0)
+	for i := len(f.tasks) - 1; i >= 0; i-- {
+		t := f.tasks[i]
+		switch {
+		case uids != nil && !slices.Contains(uids, strconv.FormatInt(t.UID, 10)),
+			statuses != nil && !slices.Contains(statuses, string(t.Status)),
+			types != nil && !slices.Contains(types, string(t.Type)),
+			indexUIDs != nil && !slices.Contains(indexUIDs, t.IndexUID):
+			continue
+		}
+		matching = append(matching, t)
+	}
+
+	return matching, nil
+}
+
+func (f *fakeMeilisearch) newTask(indexUID string, typ meilisearch.TaskType, details meilisearch.Details) *meilisearch.Task {
+	t := &meilisearch.Task{
+		UID:        int64(len(f.tasks)),
+		IndexUID:   indexUID,
+		Status:     meilisearch.TaskStatusEnqueued,
+		Type:       typ,
+		EnqueuedAt: fakeNow,
+		Details:    details,
+	}
+	if n := len(f.tasks); n != 0 {
+		t.UID = f.tasks[n-1].UID + 1
+	}
+
+	f.tasks = append(f.tasks, t)
+	return t
+}
+
+// enqueue adds a task which runs apply right away, or stays enqueued when the fake is paused.
+func (f *fakeMeilisearch) enqueue(indexUID string, typ meilisearch.TaskType, details meilisearch.Details,
+	apply func() *fakeError) *meilisearch.TaskInfo {
+	t := f.newTask(indexUID, typ, details)
+	if f.paused {
+		return fakeTaskInfo(t)
+	}
+
+	return f.process(t, func(*meilisearch.Task) *fakeError { return apply() })
+}
+
+func (f *fakeMeilisearch) process(t *meilisearch.Task, apply func(t *meilisearch.Task) *fakeError) *meilisearch.TaskInfo {
+	info := fakeTaskInfo(t)
+
+	t.Status = meilisearch.TaskStatusSucceeded
+	t.StartedAt = fakeNow
+	t.FinishedAt = fakeNow.Add(time.Second)
+	t.Duration = "PT1S"
+
+	if err := apply(t); err != nil {
+		t.Status = meilisearch.TaskStatusFailed
+		t.Error.Code = err.code
+		t.Error.Type = err.typ
+		t.Error.Message = err.msg
+		t.Error.Link = "https://docs.meilisearch.com/errors#" + err.code
+	}
+
+	return info
+}
+
+func fakeTaskInfo(t *meilisearch.Task) *meilisearch.TaskInfo {
+	return &meilisearch.TaskInfo{
+		TaskUID:    t.UID,
+		IndexUID:   t.IndexUID,
+		Status:     meilisearch.TaskStatusEnqueued,
+		Type:       t.Type,
+		EnqueuedAt: t.EnqueuedAt,
+	}
+}
+
+func (f *fakeMeilisearch) index(uid string) (*fakeIndex, *fakeError) {
+	idx, ok := f.indexes[uid]
+	if !ok {
+		return nil, &fakeError{http.StatusNotFound, "index_not_found", "invalid_request",
+			fmt.Sprintf("Index `%s` not found.", uid)}
+	}
+
+	return idx, nil
+}
+
+func (f *fakeMeilisearch) key(keyOrUID string) (*meilisearch.Key, *fakeError) {
+	for _, k := range f.keys {
+		if k.UID == keyOrUID || k.Key == keyOrUID {
+			return k, nil
+		}
+	}
+
+	return nil, &fakeError{http.StatusNotFound, "api_key_not_found", "invalid_request",
+		fmt.Sprintf("API key `%s` not found.", keyOrUID)}
+}
+
+func fakePage(r *http.Request, defaultLimit int) (int, int) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit == 0 {
+		limit = defaultLimit
+	}
+
+	return offset, limit
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeFakeError(w http.ResponseWriter, err *fakeError) {
+	writeFakeJSON(w, err.status, map[string]string{
+		"message": err.msg,
+		"code":    err.code,
+		"type":    err.typ,
+		"link":    "https://docs.meilisearch.com/errors#" + err.code,
+	})
+}
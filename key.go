@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// keyActions are the actions of Meilisearch keys, see
+// https://www.meilisearch.com/docs/reference/api/keys#actions
+var keyActions = []string{
+	"*",
+	"search",
+	"documents.*", "documents.add", "documents.get", "documents.delete",
+	"indexes.*", "indexes.create", "indexes.get", "indexes.update", "indexes.delete", "indexes.swap",
+	"tasks.*", "tasks.cancel", "tasks.delete", "tasks.get",
+	"settings.*", "settings.get", "settings.update",
+	"stats.*", "stats.get",
+	"metrics.*", "metrics.get",
+	"dumps.*", "dumps.create",
+	"snapshots.*", "snapshots.create",
+	"version",
+	"keys.create", "keys.get", "keys.update", "keys.delete",
+	"experimental.get", "experimental.update",
+}
+
+func checkKeyActions(actions []string) error {
+	for _, a := range actions {
+		if !slices.Contains(keyActions, a) {
+			return usageErrorf("unknown key action %q, use one of %s", a, strings.Join(keyActions, ", "))
+		}
+	}
+
+	return nil
+}
+
+// completeKeyActions completes the actions of a comma separated list, e.g. --actions search,doc
+func completeKeyActions(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := toComplete[:strings.LastIndex(toComplete, ",")+1]
+	given := strings.Split(prefix, ",")
+
+	actions := make([]string, 0, len(keyActions))
+	for _, a := range keyActions {
+		if !slices.Contains(given, a) {
+			actions = append(actions, prefix+a)
+		}
+	}
+
+	return actions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// durationDays matches the days and weeks of a duration with the number before
+// them, dots included so 1.5d is not read as 1. followed by 5d.
+var durationDays = regexp.MustCompile(`([\d.]+)([dw])`)
+
+// parseDuration parses Go durations with days and weeks, e.g. 30d, 2w or 1d12h.
+// Days and weeks are whole numbers, 1.5d is written 36h.
+func parseDuration(s string) (time.Duration, error) {
+	var err error
+	hours := durationDays.ReplaceAllStringFunc(s, func(m string) string {
+		n, convErr := strconv.Atoi(m[:len(m)-1])
+		if convErr != nil {
+			err = fmt.Errorf("invalid duration %q, days and weeks must be whole numbers", s)
+		}
+
+		if strings.HasSuffix(m, "w") {
+			n *= 7
+		}
+		return fmt.Sprintf("%dh", n*24)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return time.ParseDuration(hours)
+}
+
+// parseExpiry returns the expiry given by --expires-at or --expires-in, zero when none is set.
+func parseExpiry(expiresAt, expiresIn string, now time.Time) (time.Time, error) {
+	switch {
+	case len(expiresAt) != 0 && len(expiresIn) != 0:
+		return time.Time{}, usageErrorf("use either --expires-at or --expires-in")
+	case len(expiresIn) != 0:
+		d, err := parseDuration(expiresIn)
+		if err != nil {
+			return time.Time{}, usageErrorf("invalid --expires-in %q, use a duration such as 12h, 30d or 2w", expiresIn)
+		}
+
+		if d <= 0 {
+			return time.Time{}, usageErrorf("--expires-in must be greater than zero")
+		}
+		return now.Add(d), nil
+	case len(expiresAt) == 0:
+		return time.Time{}, nil
+	}
+
+	t, err := parseDate(expiresAt)
+	if err != nil {
+		return time.Time{}, usageErrorf("invalid --expires-at %q, use RFC 3339 or 2006-01-02", expiresAt)
+	}
+
+	if !t.After(now) {
+		return time.Time{}, usageErrorf("--expires-at %s is in the past", expiresAt)
+	}
+
+	return t, nil
+}
+
+// keyExpiry holds the expiry flags of the commands creating keys.
+type keyExpiry struct {
+	at    string
+	in    string
+	never bool
+}
+
+func (e *keyExpiry) addFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&e.at, "expires-at", "",
+		"Date and time when the key will expire, in RFC 3339 or as a date, e.g. 2030-01-01T00:00:00+02:00")
+	flags.StringVar(&e.in, "expires-in", "", "Duration after which the key will expire, e.g. 12h, 30d or 2w")
+	flags.BoolVar(&e.never, "no-expire", false, "create a key that never expires")
+}
+
+// parse returns the expiry set by the flags, zero with --no-expire, and whether one of them is set.
+func (e *keyExpiry) parse(now time.Time) (time.Time, bool, error) {
+	t, err := parseExpiry(e.at, e.in, now)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if e.never && !t.IsZero() {
+		return time.Time{}, false, usageErrorf("use either --no-expire or an expiry")
+	}
+
+	return t, e.never || !t.IsZero(), nil
+}
+
+// listPageLimit is the page size of the commands listing every key or index
+const listPageLimit = 100
+
+// allKeys returns the keys of every page.
+func (s *Session) allKeys() ([]meilisearch.Key, error) {
+	keys := make([]meilisearch.Key, 0)
+	for {
+		res, err := s.Client().GetKeys(&meilisearch.KeysQuery{Limit: listPageLimit, Offset: int64(len(keys))})
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, res.Results...)
+		if len(res.Results) == 0 || int64(len(keys)) >= res.Total {
+			return keys, nil
+		}
+	}
+}
+
+// findKey returns the key identified by its uid, its value or its name.
+func (s *Session) findKey(identifier string) (*meilisearch.Key, error) {
+	keys, err := s.allKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	named := make([]meilisearch.Key, 0)
+	for _, k := range keys {
+		if k.UID == identifier || k.Key == identifier {
+			return &k, nil
+		}
+
+		if k.Name == identifier {
+			named = append(named, k)
+		}
+	}
+
+	switch len(named) {
+	case 0:
+		return nil, &apiError{
+			StatusCode: http.StatusNotFound,
+			Code:       "api_key_not_found",
+			Type:       "invalid_request",
+			Message:    fmt.Sprintf("API key `%s` not found.", identifier),
+		}
+	case 1:
+		return &named[0], nil
+	}
+
+	uids := make([]string, 0, len(named))
+	for _, k := range named {
+		uids = append(uids, k.UID)
+	}
+
+	return nil, usageErrorf("%d keys are named %q, use one of their uids: %s", len(named), identifier, strings.Join(uids, ", "))
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+)
+
+const defaultExpiringWithin = "7d"
+
+// severities of the findings, from the most to the least risky
+const (
+	severityHigh   = "high"
+	severityMedium = "medium"
+	severityLow    = "low"
+	severityOK     = "ok"
+)
+
+var severities = []string{severityHigh, severityMedium, severityLow, severityOK}
+
+var keyAuditColumns = []string{"uid", "name", "risk", "checks", "expiresAt"}
+
+type keyFinding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+type keyAudit struct {
+	UID       string       `json:"uid"`
+	Name      string       `json:"name"`
+	Actions   []string     `json:"actions"`
+	Indexes   []string     `json:"indexes"`
+	ExpiresAt *time.Time   `json:"expiresAt"`
+	Risk      string       `json:"risk"`
+	Findings  []keyFinding `json:"findings"`
+}
+
+func keyAuditCmd(sess *Session) *cobra.Command {
+	within := ""
+
+	audit := &cobra.Command{
+		Use:   "audit",
+		Short: "report keys with risky permissions or expiry",
+		Long: `key audit --expiring-within 30d -o table
+
+Every key is checked for:
+  all-actions      the key grants every action (*)
+  expired          the key expired but is still present
+  all-indexes      the key grants every index (*)
+  no-expiry        the key never expires
+  missing-indexes  the key grants indexes which do not exist
+  expiring-soon    the key expires within --expiring-within`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := parseDuration(within)
+			if err != nil || d < 0 {
+				return usageErrorf("invalid --expiring-within %q, use a duration such as 7d or 48h", within)
+			}
+
+			keys, err := sess.allKeys()
+			if err != nil {
+				return err
+			}
+
+			indexes, err := sess.allIndexUIDs()
+			if err != nil {
+				return err
+			}
+
+			report := auditKeys(keys, indexes, time.Now(), d)
+
+			rows := make([]map[string]interface{}, 0, len(report))
+			for _, a := range report {
+				checks := make([]string, 0, len(a.Findings))
+				for _, f := range a.Findings {
+					checks = append(checks, f.Check)
+				}
+
+				rows = append(rows, map[string]interface{}{
+					"uid":       a.UID,
+					"name":      a.Name,
+					"risk":      a.Risk,
+					"checks":    strings.Join(checks, ", "),
+					"expiresAt": a.ExpiresAt,
+				})
+			}
+
+			sess.renderWith(report, rows, func() {
+				plainKeyAudit(report)
+			}, keyAuditColumns...)
+			return nil
+		},
+	}
+
+	audit.Flags().StringVar(&within, "expiring-within", defaultExpiringWithin,
+		"flag keys expiring within this duration, e.g. 7d or 48h")
+
+	return audit
+}
+
+// allIndexUIDs returns the uids of the indexes of every page.
+func (s *Session) allIndexUIDs() ([]string, error) {
+	uids := make([]string, 0)
+	for {
+		res, err := s.Client().GetIndexes(&meilisearch.IndexesQuery{Limit: listPageLimit, Offset: int64(len(uids))})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, idx := range res.Results {
+			uids = append(uids, idx.UID)
+		}
+
+		if len(res.Results) == 0 || int64(len(uids)) >= res.Total {
+			return uids, nil
+		}
+	}
+}
+
+// auditKeys checks the keys against the existing indexes, the riskiest keys first.
+func auditKeys(keys []meilisearch.Key, indexes []string, now time.Time, within time.Duration) []keyAudit {
+	report := make([]keyAudit, 0, len(keys))
+	for _, k := range keys {
+		a := keyAudit{
+			UID:      k.UID,
+			Name:     k.Name,
+			Actions:  k.Actions,
+			Indexes:  k.Indexes,
+			Findings: make([]keyFinding, 0),
+		}
+
+		add := func(check, severity, format string, args ...interface{}) {
+			a.Findings = append(a.Findings, keyFinding{Check: check, Severity: severity, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if slices.Contains(k.Actions, "*") {
+			add("all-actions", severityHigh, "grants every action")
+		}
+
+		switch {
+		case k.ExpiresAt.IsZero():
+		case !k.ExpiresAt.After(now):
+			add("expired", severityHigh, "expired at %s but is still present", k.ExpiresAt.UTC())
+		case k.ExpiresAt.Sub(now) <= within:
+			add("expiring-soon", severityLow, "expires at %s", k.ExpiresAt.UTC())
+		}
+
+		if slices.Contains(k.Indexes, "*") {
+			add("all-indexes", severityMedium, "grants every index")
+		}
+
+		if k.ExpiresAt.IsZero() {
+			add("no-expiry", severityMedium, "never expires")
+		}
+
+		if missing := missingIndexes(k.Indexes, indexes); len(missing) != 0 {
+			add("missing-indexes", severityLow, "grants missing indexes %s", strings.Join(missing, ", "))
+		}
+
+		if !k.ExpiresAt.IsZero() {
+			exp := k.ExpiresAt.UTC()
+			a.ExpiresAt = &exp
+		}
+
+		a.Risk = severityOK
+		for _, f := range a.Findings {
+			if slices.Index(severities, f.Severity) < slices.Index(severities, a.Risk) {
+				a.Risk = f.Severity
+			}
+		}
+
+		report = append(report, a)
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		return slices.Index(severities, report[i].Risk) < slices.Index(severities, report[j].Risk)
+	})
+
+	return report
+}
+
+// missingIndexes returns the patterns of granted which match none of the indexes.
+func missingIndexes(granted, indexes []string) []string {
+	missing := make([]string, 0)
+	for _, pattern := range granted {
+		if pattern == "*" {
+			continue
+		}
+
+		if !slices.ContainsFunc(indexes, func(uid string) bool {
+			return indexAllowed([]string{pattern}, uid)
+		}) {
+			missing = append(missing, pattern)
+		}
+	}
+
+	return missing
+}
+
+func plainKeyAudit(report []keyAudit) {
+	counts := make(map[string]int)
+	for i, a := range report {
+		counts[a.Risk]++
+
+		if i != 0 {
+			lineBreaker()
+		}
+
+		fmt.Printf("%s %s\n", a.UID, a.Name)
+		if len(a.Findings) == 0 {
+			color.Green("  ok")
+			continue
+		}
+
+		for _, f := range a.Findings {
+			line := fmt.Sprintf("  %-6s %-15s %s", f.Severity, f.Check, f.Message)
+			switch f.Severity {
+			case severityHigh:
+				color.Red("%s", line)
+			case severityMedium:
+				color.Yellow("%s", line)
+			default:
+				color.Cyan("%s", line)
+			}
+		}
+	}
+
+	fmt.Printf("\n%d keys: %d high, %d medium, %d low, %d ok\n", len(report),
+		counts[severityHigh], counts[severityMedium], counts[severityLow], counts[severityOK])
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Ja7ad/meilishell/config"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditKeys(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	report := auditKeys([]meilisearch.Key{
+		{UID: "ok", Actions: []string{"search"}, Indexes: []string{"movies"}, ExpiresAt: now.Add(30 * 24 * time.Hour)},
+		{UID: "soon", Actions: []string{"search"}, Indexes: []string{"movies"}, ExpiresAt: now.Add(time.Hour)},
+		{UID: "expired", Actions: []string{"search"}, Indexes: []string{"movies"}, ExpiresAt: now.Add(-time.Hour)},
+		{UID: "admin", Actions: []string{"*"}, Indexes: []string{"*"}},
+	}, []string{"movies"}, now, 7*24*time.Hour)
+
+	risks := make(map[string]string)
+	order := make([]string, 0)
+	for _, a := range report {
+		risks[a.UID] = a.Risk
+		order = append(order, a.UID)
+	}
+
+	require.Equal(t, []string{"expired", "admin", "soon", "ok"}, order)
+	require.Equal(t, map[string]string{"ok": "ok", "soon": "low", "expired": "high", "admin": "high"}, risks)
+	require.Equal(t, "expiring-soon", report[2].Findings[0].Check)
+}
+
+func TestMissingIndexes(t *testing.T) {
+	indexes := []string{"movies", "books_fr"}
+
+	require.Empty(t, missingIndexes([]string{"*", "movies", "books*"}, indexes))
+	require.Equal(t, []string{"songs", "albums*"}, missingIndexes([]string{"songs", "movies", "albums*"}, indexes))
+}
+
+func TestSession_AllKeys(t *testing.T) {
+	f := newFakeMeilisearch(t)
+	for i := 0; i < 150; i++ {
+		f.addKey(&meilisearch.Key{UID: fmt.Sprintf("30000000-0000-4000-8000-%012d", i), Actions: []string{"search"}})
+	}
+
+	sess := NewSession(nil)
+	_, err := sess.Connect(&config.Profile{Host: f.URL, APIKey: fakeMasterKey})
+	require.NoError(t, err)
+
+	keys, err := sess.allKeys()
+	require.NoError(t, err)
+	require.Len(t, keys, 152)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func keyRotateCmd(sess *Session) *cobra.Command {
+	expiry := &keyExpiry{}
+	grace := ""
+	keep := false
+	yes := false
+	out := ""
+	env := ""
+
+	rotate := &cobra.Command{
+		Use:   "rotate",
+		Short: "replace a key by a new one with the same permissions",
+		Long: `key rotate {uid, key or name} --grace 1h
+key rotate frontend --out .env --env MEILI_SEARCH_KEY --yes
+key rotate {uid} --expires-in 90d --keep
+
+The new key gets the name, description, actions and indexes of the old one, and
+its expiry unless --expires-at, --expires-in or --no-expire is set.
+The old key is deleted after confirmation, right away with --yes, after --grace,
+or kept with --keep.`,
+		ValidArgsFunction: sess.completeKeyUID,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("key identifier is require (uid, key or name) 'key rotate {identifier}'")
+			}
+
+			if len(env) != 0 && !envName.MatchString(env) {
+				return usageErrorf("invalid environment variable name %q", env)
+			}
+
+			wait := time.Duration(0)
+			if len(grace) != 0 {
+				d, err := parseDuration(grace)
+				if err != nil || d <= 0 {
+					return usageErrorf("invalid --grace %q, use a duration such as 10m or 1h", grace)
+				}
+				wait = d
+			}
+
+			switch {
+			case countTrue(keep, yes, wait != 0) > 1:
+				return usageErrorf("use only one of --keep, --yes and --grace")
+			case !keep && !yes && wait == 0 && !term.IsTerminal(int(os.Stdin.Fd())):
+				return usageErrorf("deleting the old key requires confirmation, use --yes, --grace or --keep")
+			}
+
+			now := time.Now()
+			t, set, err := expiry.parse(now)
+			if err != nil {
+				return err
+			}
+
+			old, err := sess.findKey(args[0])
+			if err != nil {
+				return err
+			}
+
+			if !set {
+				if !old.ExpiresAt.IsZero() && !old.ExpiresAt.After(now) {
+					return usageErrorf("key %s expired at %s, set --expires-at, --expires-in or --no-expire",
+						old.UID, old.ExpiresAt)
+				}
+				t = old.ExpiresAt
+			}
+
+			res, err := sess.Client().CreateKey(&meilisearch.Key{
+				Name:        old.Name,
+				Description: old.Description,
+				Actions:     old.Actions,
+				Indexes:     old.Indexes,
+				ExpiresAt:   t.UTC(),
+			})
+			if err != nil {
+				return err
+			}
+			sess.completions.invalidate(completeKeys)
+
+			sess.printKey(res)
+
+			// on stderr, so -o json and -o yaml print only the new key
+			status, warn := color.New(color.FgGreen), color.New(color.FgYellow)
+
+			switch {
+			case len(out) != 0:
+				if err := writeKeyFile(out, env, res.Key); err != nil {
+					return fmt.Errorf("%w, the old key %s is kept", err, old.UID)
+				}
+				status.Fprintf(os.Stderr, "key written to %s\n", out)
+			case len(env) != 0:
+				fmt.Printf("%s=%s\n", env, res.Key)
+			}
+
+			switch {
+			case old.Key == sess.APIKey():
+				warn.Fprintf(os.Stderr, "the old key %s is kept because the session uses it, "+
+					"reconnect with the new key first\n", old.UID)
+				return nil
+			case keep:
+				warn.Fprintf(os.Stderr, "the old key %s is kept, delete it with 'key delete %s'\n", old.UID, old.UID)
+				return nil
+			case wait != 0:
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+
+				stop := startSpinner(fmt.Sprintf("deleting the old key %s in %s", old.UID, wait))
+				err := sleepContext(ctx, wait)
+				stop()
+				if err != nil {
+					return fmt.Errorf("interrupted, the old key %s is kept, delete it with 'key delete %s'",
+						old.UID, old.UID)
+				}
+			case !yes && !confirm(fmt.Sprintf("Delete the old key %s?", old.UID)):
+				warn.Fprintf(os.Stderr, "the old key %s is kept, delete it with 'key delete %s'\n", old.UID, old.UID)
+				return nil
+			}
+
+			if _, err := sess.Client().DeleteKey(old.UID); err != nil {
+				return err
+			}
+			sess.completions.invalidate(completeKeys)
+
+			status.Fprintf(os.Stderr, "the old key %s is deleted\n", old.UID)
+			return nil
+		},
+	}
+
+	expiry.addFlags(rotate.Flags())
+	rotate.Flags().StringVar(&grace, "grace", "", "delete the old key after this duration, e.g. 10m or 1h")
+	rotate.Flags().BoolVar(&keep, "keep", false, "keep the old key")
+	rotate.Flags().BoolVarP(&yes, "yes", "y", false, "delete the old key without confirmation")
+	rotate.Flags().StringVar(&out, "out", "", "write the new key to a file")
+	rotate.Flags().StringVar(&env, "env", "",
+		"write the new key as {env}={key}, replacing the variable in an existing --out file")
+
+	return rotate
+}
+
+// sleepContext waits for d, or returns the error of ctx when it is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func countTrue(values ...bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+
+	return n
+}
+
+// writeKeyFile writes key to path, or sets the variable env of the env file at path.
+func writeKeyFile(path, env, key string) error {
+	if len(env) == 0 {
+		return os.WriteFile(path, []byte(key+"\n"), 0o600)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	lines := make([]string, 0)
+	if len(b) != 0 {
+		lines = strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	}
+
+	replaced := false
+	for i, l := range lines {
+		name := strings.TrimPrefix(l, "export ")
+		if strings.HasPrefix(name, env+"=") {
+			lines[i] = strings.TrimSuffix(l, name) + env + "=" + key
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		lines = append(lines, env+"="+key)
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteKeyFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "key")
+	require.NoError(t, writeKeyFile(path, "", "new"))
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "new\n", string(b))
+
+	path = filepath.Join(dir, ".env")
+	require.NoError(t, writeKeyFile(path, "MEILI_KEY", "first"))
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "MEILI_KEY=first\n", string(b))
+
+	require.NoError(t, os.WriteFile(path, []byte("HOST=localhost\nexport MEILI_KEY=old\nMEILI_KEY_UID=1"), 0o600))
+	require.NoError(t, writeKeyFile(path, "MEILI_KEY", "new"))
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "HOST=localhost\nexport MEILI_KEY=new\nMEILI_KEY_UID=1\n", string(b))
+
+	require.NoError(t, writeKeyFile(path, "OTHER_KEY", "other"))
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "HOST=localhost\nexport MEILI_KEY=new\nMEILI_KEY_UID=1\nOTHER_KEY=other\n", string(b))
+}
+
+func TestSleepContext(t *testing.T) {
+	require.NoError(t, sleepContext(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, sleepContext(ctx, time.Hour), context.Canceled)
+}
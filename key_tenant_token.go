@@ -0,0 +1,448 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+)
+
+// tenantToken is a decoded tenant token, see
+// https://www.meilisearch.com/docs/learn/security/tenant_token_reference
+type tenantToken struct {
+	Alg         string
+	APIKeyUID   string
+	SearchRules interface{}
+	ExpiresAt   time.Time
+
+	signed    string
+	signature []byte
+}
+
+// tenantTokenReport is the result of key tenant-token --decode.
+type tenantTokenReport struct {
+	APIKeyUID   string      `json:"apiKeyUid"`
+	SearchRules interface{} `json:"searchRules"`
+	ExpiresAt   *time.Time  `json:"expiresAt"`
+	Signature   string      `json:"signature"`
+	Warnings    []string    `json:"warnings"`
+	Problems    []string    `json:"problems"`
+}
+
+const (
+	signatureValid       = "valid"
+	signatureInvalid     = "invalid"
+	signatureNotVerified = "not verified"
+)
+
+func tenantTokenCmd(sess *Session) *cobra.Command {
+	apiKey := ""
+	rules := make([]string, 0)
+	rulesJSON := ""
+	expiresAt := ""
+	expiresIn := ""
+	decode := ""
+
+	token := &cobra.Command{
+		Use:   "tenant-token",
+		Short: "generate or decode a tenant token",
+		Long: `key tenant-token {api_key_uid} --rule movies:"genre = comedy" --rule books --expires-in 24h
+key tenant-token {api_key_uid} --rules '{"movies": {"filter": "user_id = 1"}}' --expires-at 2030-01-01
+key tenant-token --decode {token}
+
+The token is signed with --api-key, or with the key of the uid fetched from Meilisearch.
+--decode prints the claims of a token and checks its expiry, search rules and signature.`,
+		ValidArgsFunction: sess.completeKeyUID,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(decode) != 0 {
+				return sess.decodeTenantToken(decode, apiKey)
+			}
+
+			if len(args) == 0 {
+				return usageErrorf("api key uid is require 'key tenant-token {api_key_uid} --rule {index}:{filter}'")
+			}
+
+			searchRules, err := parseSearchRules(rulesJSON, rules)
+			if err != nil {
+				return err
+			}
+
+			exp, err := parseExpiry(expiresAt, expiresIn, time.Now())
+			if err != nil {
+				return err
+			}
+
+			secret := apiKey
+			if len(secret) == 0 {
+				k, err := sess.Client().GetKey(args[0])
+				if err != nil {
+					return err
+				}
+
+				if !canSearch(k) {
+					return fmt.Errorf("key %s can not search, tenant tokens need a key with the search action", k.UID)
+				}
+
+				if !k.ExpiresAt.IsZero() && (exp.IsZero() || exp.After(k.ExpiresAt)) {
+					color.New(color.FgYellow).Fprintf(os.Stderr, "the token stops working when its key expires at %s\n",
+						k.ExpiresAt)
+				}
+				secret = k.Key
+			}
+
+			res, err := sess.Client().GenerateTenantToken(args[0], searchRules, &meilisearch.TenantTokenOptions{
+				APIKey:    secret,
+				ExpiresAt: exp,
+			})
+			if err != nil {
+				return usageErrorf("%s", strings.TrimPrefix(err.Error(), "GenerateTenantToken: "))
+			}
+
+			var expires *time.Time
+			if !exp.IsZero() {
+				expires = &exp
+			}
+
+			sess.render(map[string]interface{}{
+				"token":       res,
+				"apiKeyUid":   args[0],
+				"searchRules": searchRules,
+				"expiresAt":   expires,
+			}, func() {
+				fmt.Println(res)
+			}, "token", "apiKeyUid", "expiresAt")
+			return nil
+		},
+	}
+
+	token.Flags().StringVar(&apiKey, "api-key", "", "parent key signing the token, fetched by its uid when not set")
+	token.Flags().StringArrayVar(&rules, "rule", nil, "search rule as {index} or {index}:{filter}, can be repeated")
+	token.Flags().StringVar(&rulesJSON, "rules", "", "search rules in JSON, e.g. '{\"movies\": {\"filter\": \"user_id = 1\"}}'")
+	token.Flags().StringVar(&expiresAt, "expires-at", "", "expiry of the token in RFC 3339, e.g. 2030-01-01T00:00:00Z")
+	token.Flags().StringVar(&expiresIn, "expires-in", "", "expiry of the token from now, e.g. 24h or 30d")
+	token.Flags().StringVar(&decode, "decode", "", "print the claims of a token and validate them")
+
+	return token
+}
+
+// parseSearchRules merges the JSON rules and the --rule flags, which are given
+// as {index} to search every document or {index}:{filter}.
+func parseSearchRules(rulesJSON string, rules []string) (map[string]interface{}, error) {
+	searchRules := make(map[string]interface{})
+
+	if len(rulesJSON) != 0 {
+		var v interface{}
+		if err := json.Unmarshal([]byte(rulesJSON), &v); err != nil {
+			return nil, usageErrorf("invalid --rules: %s", err.Error())
+		}
+
+		parsed, err := searchRulesOf(v)
+		if err != nil {
+			return nil, usageErrorf("invalid --rules: %s", err.Error())
+		}
+		searchRules = parsed
+	}
+
+	for _, rule := range rules {
+		index, filter, _ := strings.Cut(rule, ":")
+		index, filter = strings.TrimSpace(index), strings.TrimSpace(filter)
+		if len(index) == 0 {
+			return nil, usageErrorf("invalid --rule %q, use {index} or {index}:{filter}", rule)
+		}
+
+		if len(filter) == 0 {
+			searchRules[index] = map[string]interface{}{}
+			continue
+		}
+		searchRules[index] = map[string]interface{}{"filter": filter}
+	}
+
+	if len(searchRules) == 0 {
+		return nil, usageErrorf("search rules are require, use --rule {index}:{filter} or --rules {json}")
+	}
+
+	return searchRules, nil
+}
+
+// searchRulesOf checks the search rules of a token, an array of index uids or
+// an object of index uids to null or to an object with an optional filter.
+func searchRulesOf(v interface{}) (map[string]interface{}, error) {
+	searchRules := make(map[string]interface{})
+
+	switch rules := v.(type) {
+	case []interface{}:
+		for _, index := range rules {
+			uid, ok := index.(string)
+			if !ok || len(uid) == 0 {
+				return nil, fmt.Errorf("index %s must be a non empty string", settingValue(index))
+			}
+			searchRules[uid] = map[string]interface{}{}
+		}
+	case map[string]interface{}:
+		for uid, rule := range rules {
+			switch r := rule.(type) {
+			case nil:
+			case map[string]interface{}:
+				if filter, ok := r["filter"]; ok && !isFilter(filter) {
+					return nil, fmt.Errorf("filter of %s must be a string or an array", uid)
+				}
+			default:
+				return nil, fmt.Errorf("rule of %s must be null or an object", uid)
+			}
+			searchRules[uid] = rule
+		}
+	default:
+		return nil, errors.New("search rules must be an object or an array")
+	}
+
+	return searchRules, nil
+}
+
+func isFilter(v interface{}) bool {
+	switch v.(type) {
+	case nil, string, []interface{}:
+		return true
+	}
+
+	return false
+}
+
+func canSearch(k *meilisearch.Key) bool {
+	return slices.Contains(k.Actions, "*") || slices.Contains(k.Actions, "search")
+}
+
+// parseTenantToken decodes a token without verifying it.
+func parseTenantToken(token string) (*tenantToken, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("a tenant token has 3 parts separated by dots")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeTokenPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+
+	var claims struct {
+		APIKeyUID   string      `json:"apiKeyUid"`
+		SearchRules interface{} `json:"searchRules"`
+		Exp         *int64      `json:"exp"`
+	}
+	if err := decodeTokenPart(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+
+	t := &tenantToken{
+		Alg:         header.Alg,
+		APIKeyUID:   claims.APIKeyUID,
+		SearchRules: claims.SearchRules,
+		signed:      parts[0] + "." + parts[1],
+		signature:   signature,
+	}
+	if claims.Exp != nil {
+		t.ExpiresAt = time.Unix(*claims.Exp, 0).UTC()
+	}
+
+	return t, nil
+}
+
+func decodeTokenPart(part string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// verify reports whether the token is signed with the key.
+func (t *tenantToken) verify(key string) bool {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(t.signed))
+	return hmac.Equal(t.signature, mac.Sum(nil))
+}
+
+// check returns the problems making the token unusable and the warnings about
+// it. key is the parent key of the token, nil when it is unknown.
+func (t *tenantToken) check(key *meilisearch.Key, now time.Time) (problems, warnings []string) {
+	problems, warnings = make([]string, 0), make([]string, 0)
+
+	if t.Alg != "HS256" {
+		problems = append(problems, fmt.Sprintf("algorithm %q is not supported, use HS256", t.Alg))
+	}
+
+	if !meilisearch.IsValidUUID(t.APIKeyUID) {
+		problems = append(problems, fmt.Sprintf("apiKeyUid %q is not a uuid v4", t.APIKeyUID))
+	}
+
+	rules, err := searchRulesOf(t.SearchRules)
+	switch {
+	case err != nil:
+		problems = append(problems, err.Error())
+	case len(rules) == 0:
+		problems = append(problems, "search rules are empty, the token can not search any index")
+	}
+
+	switch {
+	case t.ExpiresAt.IsZero():
+		warnings = append(warnings, "the token never expires")
+	case !t.ExpiresAt.After(now):
+		problems = append(problems, fmt.Sprintf("the token expired at %s", t.ExpiresAt))
+	}
+
+	if key == nil {
+		return problems, warnings
+	}
+
+	if !key.ExpiresAt.IsZero() && !key.ExpiresAt.After(now) {
+		problems = append(problems, fmt.Sprintf("the key %s expired at %s", key.UID, key.ExpiresAt))
+	}
+
+	if !canSearch(key) {
+		problems = append(problems, fmt.Sprintf("the key %s can not search", key.UID))
+	}
+
+	for _, uid := range sortedKeys(rules) {
+		if !indexAllowed(key.Indexes, uid) {
+			warnings = append(warnings, fmt.Sprintf("the key %s can not search %s", key.UID, uid))
+		}
+	}
+
+	return problems, warnings
+}
+
+// indexAllowed reports whether uid matches one of the indexes of a key, which
+// can end with * to match every index with the prefix.
+func indexAllowed(indexes []string, uid string) bool {
+	for _, index := range indexes {
+		prefix, wildcard := strings.CutSuffix(index, "*")
+		if index == uid || wildcard && strings.HasPrefix(uid, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// decodeTenantToken prints the claims of a token and returns an error when it
+// is not usable. The signature is verified with apiKey, or with the parent key
+// fetched from Meilisearch when apiKey is not set.
+func (s *Session) decodeTenantToken(token, apiKey string) error {
+	t, err := parseTenantToken(token)
+	if err != nil {
+		return usageErrorf("invalid tenant token: %s", err.Error())
+	}
+
+	var key *meilisearch.Key
+	var keyErr error
+	if meilisearch.IsValidUUID(t.APIKeyUID) {
+		key, keyErr = s.Client().GetKey(t.APIKeyUID)
+	}
+
+	report := &tenantTokenReport{
+		APIKeyUID:   t.APIKeyUID,
+		SearchRules: t.SearchRules,
+		Signature:   signatureNotVerified,
+	}
+	if !t.ExpiresAt.IsZero() {
+		report.ExpiresAt = &t.ExpiresAt
+	}
+
+	report.Problems, report.Warnings = t.check(key, time.Now())
+
+	var ae *apiError
+	switch {
+	case keyErr == nil:
+	case errors.As(wrapError(keyErr), &ae) && ae.StatusCode == 404:
+		report.Problems = append(report.Problems, fmt.Sprintf("the key %s does not exist", t.APIKeyUID))
+	case len(apiKey) == 0:
+		// neither the key nor the signature can be checked
+		return wrapError(keyErr)
+	default:
+		report.Warnings = append(report.Warnings, fmt.Sprintf("the key %s is not checked: %s", t.APIKeyUID,
+			wrapError(keyErr).Error()))
+	}
+
+	secret := apiKey
+	if len(secret) == 0 && key != nil {
+		secret = key.Key
+	}
+
+	switch {
+	case len(secret) == 0:
+		report.Warnings = append(report.Warnings, "the signature is not verified, set --api-key")
+	case t.verify(secret):
+		report.Signature = signatureValid
+	default:
+		report.Signature = signatureInvalid
+		report.Problems = append(report.Problems, "the signature does not match the key")
+	}
+
+	s.render(report, func() {
+		plainTenantToken(report)
+	}, "apiKeyUid", "expiresAt", "signature")
+
+	if len(report.Problems) != 0 {
+		return fmt.Errorf("invalid tenant token: %s", strings.Join(report.Problems, ", "))
+	}
+	return nil
+}
+
+func plainTenantToken(r *tenantTokenReport) {
+	expires := "never"
+	if r.ExpiresAt != nil {
+		expires = r.ExpiresAt.String()
+	}
+
+	fmt.Printf("API Key UID: %s\nSearch Rules:\n", r.APIKeyUID)
+
+	rules, err := searchRulesOf(r.SearchRules)
+	if err != nil {
+		fmt.Printf("  %s\n", settingValue(r.SearchRules))
+	}
+
+	for _, uid := range sortedKeys(rules) {
+		filter := "all documents"
+		if rule, ok := rules[uid].(map[string]interface{}); ok && rule["filter"] != nil {
+			filter = settingValue(rule["filter"])
+			if s, ok := rule["filter"].(string); ok {
+				filter = s
+			}
+		}
+		fmt.Printf("  %s: %s\n", uid, filter)
+	}
+
+	fmt.Printf("Expires At: %s\nSignature: %s\n", expires, r.Signature)
+
+	for _, w := range r.Warnings {
+		color.New(color.FgYellow).Fprintln(os.Stderr, w)
+	}
+}
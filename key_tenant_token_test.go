@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSearchRules(t *testing.T) {
+	rules, err := parseSearchRules(`{"movies": {"filter": "genre = comedy"}, "books": null}`,
+		[]string{"songs", "movies:user_id = 1 AND tags IN [a, b]"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"movies": map[string]interface{}{"filter": "user_id = 1 AND tags IN [a, b]"},
+		"books":  nil,
+		"songs":  map[string]interface{}{},
+	}, rules)
+
+	rules, err = parseSearchRules(`["movies", "books*"]`, nil)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	for _, invalid := range []struct {
+		json  string
+		rules []string
+	}{
+		{json: "", rules: nil},
+		{json: `"movies"`},
+		{json: `{"movies": "genre = comedy"}`},
+		{json: `{"movies": {"filter": 1}}`},
+		{json: `[1]`},
+		{rules: []string{":genre = comedy"}},
+	} {
+		_, err := parseSearchRules(invalid.json, invalid.rules)
+		require.Equal(t, exitUsage, exitCode(err), invalid)
+	}
+}
+
+func TestTenantToken_Check(t *testing.T) {
+	const uid = "10000000-0000-4000-8000-000000000001"
+
+	client := meilisearch.NewClient(meilisearch.ClientConfig{Host: "http://localhost:7700"})
+	token, err := client.GenerateTenantToken(uid, map[string]interface{}{"movies": nil, "books": nil},
+		&meilisearch.TenantTokenOptions{APIKey: "secret", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	tt, err := parseTenantToken(token)
+	require.NoError(t, err)
+	require.Equal(t, uid, tt.APIKeyUID)
+	require.True(t, tt.verify("secret"))
+	require.False(t, tt.verify("other"))
+
+	key := &meilisearch.Key{UID: uid, Actions: []string{"search"}, Indexes: []string{"movies*"}}
+	problems, warnings := tt.check(key, time.Now())
+	require.Empty(t, problems)
+	require.Equal(t, []string{"the key " + uid + " can not search books"}, warnings)
+
+	problems, _ = tt.check(key, time.Now().Add(2*time.Hour))
+	require.Len(t, problems, 1)
+	require.Contains(t, problems[0], "the token expired at")
+
+	key.Actions = []string{"documents.add"}
+	key.ExpiresAt = time.Now().Add(-time.Hour)
+	problems, _ = tt.check(key, time.Now())
+	require.Len(t, problems, 2)
+}
+
+func TestIndexAllowed(t *testing.T) {
+	require.True(t, indexAllowed([]string{"*"}, "movies"))
+	require.True(t, indexAllowed([]string{"books", "movies"}, "movies"))
+	require.True(t, indexAllowed([]string{"movies*"}, "movies_2024"))
+	require.False(t, indexAllowed([]string{"movies"}, "movies_2024"))
+	require.False(t, indexAllowed(nil, "movies"))
+}
+
+func TestDecodeTenantToken_KeyError(t *testing.T) {
+	f, sess := connectFake(t)
+	f.Close()
+
+	// the key can not be fetched, only the signature is checked with --api-key
+	out := runLines(t, newRootCmd(sess),
+		"key tenant-token --decode "+validTenantToken,
+		"key tenant-token --decode "+validTenantToken+" --api-key "+fakeKeyOf("10000000-0000-4000-8000-000000000001"),
+	)
+	require.Contains(t, out, "(exit 6)")
+	require.Equal(t, 1, strings.Count(out, "error:"))
+	require.Contains(t, out, "Signature: valid")
+}
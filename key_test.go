@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"90m":   90 * time.Minute,
+		"30d":   30 * 24 * time.Hour,
+		"2w":    14 * 24 * time.Hour,
+		"1d12h": 36 * time.Hour,
+	} {
+		d, err := parseDuration(s)
+		require.NoError(t, err, s)
+		require.Equal(t, want, d, s)
+	}
+
+	for _, s := range []string{"", "30", "d", "1y", "1.5d", "0.5w", "1.5.5d", "1h.5d"} {
+		_, err := parseDuration(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	exp, err := parseExpiry("", "", now)
+	require.NoError(t, err)
+	require.True(t, exp.IsZero())
+
+	exp, err = parseExpiry("", "30d", now)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(30*24*time.Hour), exp)
+
+	exp, err = parseExpiry("2024-02-01", "", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), exp)
+
+	exp, err = parseExpiry("2024-02-01T02:00:00+02:00", "", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), exp.UTC())
+
+	for _, invalid := range [][2]string{
+		{"2023-12-31", ""},
+		{"2024-02-01", "1h"},
+		{"tomorrow", ""},
+		{"", "-1h"},
+		{"", "1y"},
+	} {
+		_, err = parseExpiry(invalid[0], invalid[1], now)
+		require.Equal(t, exitUsage, exitCode(err), invalid)
+	}
+}
+
+func TestCheckKeyActions(t *testing.T) {
+	require.NoError(t, checkKeyActions([]string{"search", "documents.*", "*"}))
+	require.Equal(t, exitUsage, exitCode(checkKeyActions([]string{"search", "document.add"})))
+}
+
+func TestCompleteKeyActions(t *testing.T) {
+	actions, _ := completeKeyActions(nil, nil, "")
+	require.Equal(t, keyActions, actions)
+
+	actions, _ = completeKeyActions(nil, nil, "search,doc")
+	require.Contains(t, actions, "search,documents.add")
+	require.NotContains(t, actions, "search,search")
+}
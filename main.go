@@ -1,8 +1,12 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/Ja7ad/meilishell/config"
 	"github.com/Ja7ad/meilishell/shell"
 	"github.com/Ja7ad/meilishell/util"
 	"github.com/c-bata/go-prompt"
@@ -10,11 +14,11 @@ import (
 	"github.com/inancgumus/screen"
 	"github.com/meilisearch/meilisearch-go"
 	"github.com/spf13/cobra"
-	"log"
-	"net/url"
+	"golang.org/x/term"
+	"io"
 	"os"
+	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -27,6 +31,11 @@ const header = `Welcome to MeiliShell %s | https://github.com/Ja7ad/meilishell
 - Docs: https://www.meilisearch.com/docs/reference/api/overview
 `
 
+const (
+	historyFile  = ".meilishell_history"
+	historyLimit = 1000
+)
+
 const (
 	major = 0
 	minor = 2
@@ -37,120 +46,271 @@ func version() string {
 	return fmt.Sprintf("v%d.%d.%d", major, minor, patch)
 }
 
-var (
-	_prefix = ""
-	client  *meilisearch.Client
-)
-
 func main() {
-	root := &cobra.Command{
-		Use:   "meilishell",
-		Short: "Meilisearch shell",
+	history, err := shell.NewHistory(historyPath(), historyLimit)
+	if err != nil {
+		color.Yellow("failed to load history: %s", err.Error())
+	}
+
+	conf, err := loadConfig()
+	if err != nil {
+		color.Yellow("failed to load config: %s", err.Error())
 	}
 
-	sh := shell.New(root, nil,
+	sess := NewSession(conf)
+	root := newRootCmd(sess)
+
+	sh := shell.New(root, nil, history, printError,
 		prompt.OptionSuggestionBGColor(prompt.Black),
 		prompt.OptionSuggestionTextColor(prompt.Green),
 		prompt.OptionDescriptionBGColor(prompt.Black),
 		prompt.OptionDescriptionTextColor(prompt.White),
-		prompt.OptionLivePrefix(livePrefix),
+		prompt.OptionLivePrefix(sess.livePrefix),
 	)
 
 	h := sh.PersistentFlags().String("host", "http://localhost:7700", "set meilisearch host")
 	k := sh.PersistentFlags().String("api-key", "", "set meilisearch api key or master key "+
 		"(https://www.meilisearch.com/docs/reference/api/keys)")
+	sh.PersistentFlags().StringVarP(&sess.output, "output", "o", outputPlain,
+		"set default output format of commands (plain, json, yaml, table)")
+	pn := sh.PersistentFlags().String("profile", "", "connect with a profile of the config file, "+
+		"the current profile is used when --host is not set")
+
+	command := ""
+	sh.Flags().StringVarP(&command, "command", "c", "", "run a single command and exit, e.g. -c \"index list\"")
+
+	// without a terminal on stdin the shell reads a script from it instead of prompting
+	interactive := func(cmd *cobra.Command) bool {
+		return cmd == sh && len(command) == 0 && term.IsTerminal(int(os.Stdin.Fd()))
+	}
+
+	sh.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := checkOutputFormat(sess.output); err != nil {
+			return err
+		}
+
+		p := &config.Profile{Host: *h, APIKey: *k}
+
+		name := *pn
+		if len(name) == 0 && !cmd.Flags().Changed("host") {
+			name = sess.conf.Current
+		}
+
+		if len(name) != 0 {
+			prof, ok := sess.conf.Profile(name)
+			if !ok {
+				return usageErrorf("profile %q not found in %s", name, sess.conf.Path())
+			}
+
+			cp := *prof
+			p = &cp
+			if cmd.Flags().Changed("api-key") {
+				p.APIKey = *k
+			}
+		}
+
+		ver, err := sess.Connect(p)
+		if err != nil {
+			return err
+		}
+
+		if interactive(cmd) {
+			cleanSc()
+			printHeader(ver)
+		}
+		return nil
+	}
+
+	startPrompt := sh.Run
+	sh.Run = nil
+	sh.RunE = func(cmd *cobra.Command, args []string) error {
+		switch {
+		case len(command) != 0:
+			return shell.Exec(root, command)
+		case !interactive(cmd):
+			return shell.RunScript(root, os.Stdin, false, printError)
+		}
+
+		startPrompt(cmd, args)
+		return nil
+	}
+
+	sh.AddCommand(runCmd(root))
+	usageErrors(sh)
+
+	// errors are printed by printError, failed script lines as soon as they fail
+	sh.SilenceErrors = true
+	sh.SilenceUsage = true
 
-	sh.PreRun = func(cmd *cobra.Command, args []string) {
-		connect(*h, *k, cleanSc, true)
+	if err := sh.Execute(); err != nil {
+		var le *shell.LineError
+		if !errors.As(err, &le) {
+			printError(err)
+		}
+		os.Exit(exitCode(err))
 	}
+}
 
-	idxCmd := indexCmd()
+// newRootCmd returns the commands of the shell, all bound to sess.
+func newRootCmd(sess *Session) *cobra.Command {
+	root := &cobra.Command{
+		Use:   "meilishell",
+		Short: "Meilisearch shell",
+	}
 
-	root.AddCommand(healthCmd())
+	root.PersistentFlags().StringVarP(&sess.outputFlag, "output", "o", "",
+		"set output format (plain, json, yaml, table)")
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return checkOutputFormat(sess.outputFlag)
+	}
+
+	idxCmd := indexCmd(sess)
+
+	root.AddCommand(healthCmd(sess))
 	root.AddCommand(clsCmd())
 	root.AddCommand(idxCmd)
-	root.AddCommand(KeyCmd())
-	root.AddCommand(versionCmd())
-	root.AddCommand(statsCmd())
-	root.AddCommand(dumpCmd())
-	root.AddCommand(connectCmd())
-	root.AddCommand(taskCmd())
-	root.AddCommand(indexSettingsCmd(idxCmd))
-
-	// TODO currently not support search in shell
-	root.AddCommand(multiSearchCmd())
-	root.AddCommand(searchCmd())
-	root.AddCommand(facetSearch())
+	root.AddCommand(KeyCmd(sess))
+	root.AddCommand(versionCmd(sess))
+	root.AddCommand(statsCmd(sess))
+	root.AddCommand(dumpCmd(sess))
+	root.AddCommand(connectCmd(sess))
+	root.AddCommand(profileCmd(sess))
+	root.AddCommand(taskCmd(sess))
+	root.AddCommand(indexSettingsCmd(sess, idxCmd))
+	root.AddCommand(documentCmd(sess))
+
+	root.AddCommand(multiSearchCmd(sess))
+	root.AddCommand(searchCmd(sess))
+	root.AddCommand(facetSearch(sess))
+
+	wrapErrors(root)
+	usageErrors(root)
+
+	root.SilenceErrors = true
+	root.SilenceUsage = true
+
+	return root
+}
 
-	if err := sh.Execute(); err != nil {
-		log.Fatal(err)
+func runCmd(root *cobra.Command) *cobra.Command {
+	keepGoing := false
+
+	run := &cobra.Command{
+		Use:   "run [script]",
+		Short: "run the commands of a script file, or of stdin when no file is given",
+		Long: `meilishell run setup.msh
+cat setup.msh | meilishell run
+
+Every line is a shell command, blank lines and lines starting with # are skipped.
+The first failing line stops the script unless --keep-going is set.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 || args[0] == "-" {
+				return shell.RunScript(root, os.Stdin, keepGoing, printError)
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			return shell.RunScript(root, f, keepGoing, printError)
+		},
 	}
+
+	run.Flags().BoolVar(&keepGoing, "keep-going", false, "run the remaining lines after a failure")
+
+	return run
 }
 
-func livePrefix() (string, bool) {
-	return _prefix, true
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, historyFile)
 }
 
 func clsCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "clear",
 		Short: "Clear screen",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			cleanSc()
+			return nil
 		},
 	}
 }
 
-func connectCmd() *cobra.Command {
+func connectCmd(sess *Session) *cobra.Command {
 	key := ""
 
 	c := &cobra.Command{
 		Use:   "connect",
 		Short: "connect to another Meilisearch",
-		Long:  "connect http://localhost:7700 --api-key foobar",
+		Long: `connect http://localhost:7700 --api-key foobar
+connect {profile_name}`,
 	}
 
 	c.Flags().StringVar(&key, "api-key", "", "set meilisearch api key or master key "+
 		"(https://www.meilisearch.com/docs/reference/api/keys)")
 
-	c.Run = func(cmd *cobra.Command, args []string) {
+	c.RunE = func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
-			color.Red("host is require as argument, for example 'connect http://localhost:7700 --api-key foobar'")
-			return
+			return usageErrorf("host is require as argument, for example 'connect http://localhost:7700 --api-key foobar'")
+		}
+
+		p := &config.Profile{Host: args[0], APIKey: key}
+		if prof, ok := sess.conf.Profile(args[0]); ok {
+			cp := *prof
+			p = &cp
+			if cmd.Flags().Changed("api-key") {
+				p.APIKey = key
+			}
+		}
+
+		ver, err := sess.Connect(p)
+		if err != nil {
+			return err
 		}
 
-		connect(args[0], key, nil, false)
+		printHeader(ver)
+		return nil
 	}
 
 	return c
 }
 
-func indexCmd() *cobra.Command {
+func indexCmd(sess *Session) *cobra.Command {
 	idx := &cobra.Command{
 		Use:   "index",
 		Short: "manage index",
 	}
 
 	get := &cobra.Command{
-		Use:   "get",
-		Short: "get an index",
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:               "get",
+		Short:             "get an index",
+		ValidArgsFunction: sess.completeIndexUID,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index get {uid}'")
-				return
+				return usageErrorf("index uid is require 'index get {uid}'")
 			}
 
-			resp, err := client.GetIndex(args[0])
+			resp, err := sess.Client().GetIndex(args[0])
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			fmt.Printf(`Index UID: %s
+			sess.render(resp, func() {
+				fmt.Printf(`Index UID: %s
 Primary Key: %s
 Created At: %s
 Updated At: %s
 `, resp.UID, resp.PrimaryKey, resp.CreatedAt, resp.UpdatedAt)
+			}, indexColumns...)
+			return nil
 		},
 	}
 
@@ -159,29 +319,31 @@ Updated At: %s
 	list := &cobra.Command{
 		Use:   "list",
 		Short: "list of indexes",
-		Run: func(cmd *cobra.Command, args []string) {
-			res, err := client.GetIndexes(&meilisearch.IndexesQuery{
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := sess.Client().GetIndexes(&meilisearch.IndexesQuery{
 				Limit:  limit,
 				Offset: offset,
 			})
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			sort.Slice(res.Results, func(i, j int) bool {
 				return i > j
 			})
 
-			for i, result := range res.Results {
-				fmt.Printf(`No: %d
+			sess.render(res.Results, func() {
+				for i, result := range res.Results {
+					fmt.Printf(`No: %d
 Index UID: %s
 Primary Key: %s
 Created At: %s
 Updated At: %s
 `, i+1, result.UID, result.PrimaryKey, result.CreatedAt, result.UpdatedAt)
-				lineBreaker()
-			}
+					lineBreaker()
+				}
+			}, indexColumns...)
+			return nil
 		},
 	}
 
@@ -189,45 +351,45 @@ Updated At: %s
 	list.Flags().Int64Var(&offset, "offset", 0, "set offset for list of index")
 
 	primaryKey := ""
+	wait := false
+	waitTimeout := time.Duration(0)
 
 	create := &cobra.Command{
 		Use:   "create",
 		Short: "create an index",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index create {uid}'")
-				return
+				return usageErrorf("index uid is require 'index create {uid}'")
 			}
-			resp, err := client.CreateIndex(&meilisearch.IndexConfig{
+			resp, err := sess.Client().CreateIndex(&meilisearch.IndexConfig{
 				Uid:        args[0],
 				PrimaryKey: primaryKey,
 			})
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(resp)
+			return sess.finishTask(resp, wait, waitTimeout, completeIndexes, completeTasks)
 		},
 	}
 
 	create.Flags().StringVar(&primaryKey, "primary-key", "", "set primary key")
+	addWaitFlags(create.Flags(), &wait, &waitTimeout)
 
 	del := &cobra.Command{
-		Use:   "delete",
-		Short: "delete an index",
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:               "delete",
+		Short:             "delete an index",
+		ValidArgsFunction: sess.completeIndexUID,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index create {uid}'")
-				return
+				return usageErrorf("index uid is require 'index create {uid}'")
 			}
-			resp, err := client.DeleteIndex(args[0])
+			resp, err := sess.Client().DeleteIndex(args[0])
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(resp)
+			return sess.finishTask(resp, wait, waitTimeout, completeIndexes, completeTasks)
 		},
 	}
 
@@ -235,10 +397,9 @@ Updated At: %s
 		Use:   "swap",
 		Short: "swap indexes",
 		Long:  "swap foo,bar a,b x,y",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'swap foo,bar x,y a,b'")
-				return
+				return usageErrorf("index uid is require 'swap foo,bar x,y a,b'")
 			}
 
 			swaps := make([]meilisearch.SwapIndexesParams, 0)
@@ -252,15 +413,18 @@ Updated At: %s
 				}
 			}
 
-			res, err := client.SwapIndexes(swaps)
+			res, err := sess.Client().SwapIndexes(swaps)
 			if err != nil {
-				color.Red(err.Error())
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout, completeIndexes, completeTasks)
 		},
 	}
 
+	addWaitFlags(del.Flags(), &wait, &waitTimeout)
+	addWaitFlags(swap.Flags(), &wait, &waitTimeout)
+
 	idx.AddCommand(get)
 	idx.AddCommand(list)
 	idx.AddCommand(create)
@@ -270,7 +434,7 @@ Updated At: %s
 	return idx
 }
 
-func indexSettingsCmd(idxCmd *cobra.Command) *cobra.Command {
+func indexSettingsCmd(sess *Session, idxCmd *cobra.Command) *cobra.Command {
 	settings := &cobra.Command{
 		Use:   "settings",
 		Short: "manage settings",
@@ -279,290 +443,302 @@ func indexSettingsCmd(idxCmd *cobra.Command) *cobra.Command {
 	get := &cobra.Command{
 		Use:   "get",
 		Short: "get settings",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetSettings()
+			res, err := sess.Client().Index(args[0]).GetSettings()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printSettings(res)
+			sess.printSettings(res)
+			return nil
 		},
 	}
 
 	getRankingRules := &cobra.Command{
 		Use:   "ranking-rules",
 		Short: "get ranking rules",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get ranking-rules {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get ranking-rules {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetRankingRules()
+			res, err := sess.Client().Index(args[0]).GetRankingRules()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			if res != nil {
-				fmt.Printf("Ranking Rules: %v\n", strings.Join(*res, ","))
+				sess.render(res, func() {
+					fmt.Printf("Ranking Rules: %v\n", strings.Join(*res, ","))
+				})
 			}
+			return nil
 		},
 	}
 
 	getDistinctAttribute := &cobra.Command{
 		Use:   "distinct-attribute",
 		Short: "get distinct attribute",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get distinct-attribute {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get distinct-attribute {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetDistinctAttribute()
+			res, err := sess.Client().Index(args[0]).GetDistinctAttribute()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			if res != nil {
-				fmt.Printf("Distinct Attribute: %s\n", *res)
+				sess.render(res, func() {
+					fmt.Printf("Distinct Attribute: %s\n", *res)
+				})
 			}
+			return nil
 		},
 	}
 
 	getSearchableAttributes := &cobra.Command{
 		Use:   "searchable-attributes",
 		Short: "get searchable attributes",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get searchable-attributes {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get searchable-attributes {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetSearchableAttributes()
+			res, err := sess.Client().Index(args[0]).GetSearchableAttributes()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			if res != nil {
-				fmt.Printf("Searchable Attributes: %s\n", strings.Join(*res, ","))
+				sess.render(res, func() {
+					fmt.Printf("Searchable Attributes: %s\n", strings.Join(*res, ","))
+				})
 			}
+			return nil
 		},
 	}
 
 	getDisplayedAttributes := &cobra.Command{
 		Use:   "displayed-attributes",
 		Short: "get displayed attributes",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get displayed-attributes {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get displayed-attributes {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetDisplayedAttributes()
+			res, err := sess.Client().Index(args[0]).GetDisplayedAttributes()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			if res != nil {
-				fmt.Printf("Displayed Attributes: %s\n", strings.Join(*res, ","))
+				sess.render(res, func() {
+					fmt.Printf("Displayed Attributes: %s\n", strings.Join(*res, ","))
+				})
 			}
+			return nil
 		},
 	}
 
 	getStopWords := &cobra.Command{
 		Use:   "stop-words",
 		Short: "get stop words",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get stop-words {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get stop-words {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetStopWords()
+			res, err := sess.Client().Index(args[0]).GetStopWords()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			if res != nil {
-				fmt.Printf("Stop Words: %s\n", strings.Join(*res, ","))
+				sess.render(res, func() {
+					fmt.Printf("Stop Words: %s\n", strings.Join(*res, ","))
+				})
 			}
+			return nil
 		},
 	}
 
 	getSynonyms := &cobra.Command{
 		Use:   "synonyms",
 		Short: "get synonyms",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get synonyms {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get synonyms {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetSynonyms()
+			res, err := sess.Client().Index(args[0]).GetSynonyms()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			if res != nil {
-				fmt.Printf("Synonyms: %+v\n", *res)
+				sess.render(res, func() {
+					fmt.Printf("Synonyms: %+v\n", *res)
+				})
 			}
+			return nil
 		},
 	}
 
 	getFilterableAttributes := &cobra.Command{
 		Use:   "filterable-attributes",
 		Short: "get filterable attributes",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get filterable-attributes {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get filterable-attributes {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetFilterableAttributes()
+			res, err := sess.Client().Index(args[0]).GetFilterableAttributes()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			if res != nil {
-				fmt.Printf("Filterable Attributes: %s\n", strings.Join(*res, ","))
+				sess.render(res, func() {
+					fmt.Printf("Filterable Attributes: %s\n", strings.Join(*res, ","))
+				})
 			}
+			return nil
 		},
 	}
 
 	getSortableAttributes := &cobra.Command{
 		Use:   "sortable-attributes",
 		Short: "get sortable attributes",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get sortable-attributes {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get sortable-attributes {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetSortableAttributes()
+			res, err := sess.Client().Index(args[0]).GetSortableAttributes()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			if res != nil {
-				fmt.Printf("Sortable Attributes: %s\n", strings.Join(*res, ","))
+				sess.render(res, func() {
+					fmt.Printf("Sortable Attributes: %s\n", strings.Join(*res, ","))
+				})
 			}
+			return nil
 		},
 	}
 
 	getTypoTolerance := &cobra.Command{
 		Use:   "typo-tolerance",
 		Short: "get typo-tolerance",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get typo-tolerance {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get typo-tolerance {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetTypoTolerance()
+			res, err := sess.Client().Index(args[0]).GetTypoTolerance()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			if res != nil {
-				fmt.Printf("Typo Tolerance: %+v\n", *res)
+				sess.render(res, func() {
+					fmt.Printf("Typo Tolerance: %+v\n", *res)
+				})
 			}
+			return nil
 		},
 	}
 
 	getPagination := &cobra.Command{
 		Use:   "pagination",
 		Short: "get pagination",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get pagination {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get pagination {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetPagination()
+			res, err := sess.Client().Index(args[0]).GetPagination()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			if res != nil {
-				fmt.Printf("Pagination: %+v\n", *res)
+				sess.render(res, func() {
+					fmt.Printf("Pagination: %+v\n", *res)
+				})
 			}
+			return nil
 		},
 	}
 
 	getFaceting := &cobra.Command{
 		Use:   "faceting",
 		Short: "get faceting",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get faceting {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get faceting {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetFaceting()
+			res, err := sess.Client().Index(args[0]).GetFaceting()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			if res != nil {
-				fmt.Printf("Faceting: %+v\n", *res)
+				sess.render(res, func() {
+					fmt.Printf("Faceting: %+v\n", *res)
+				})
 			}
+			return nil
 		},
 	}
 
 	getEmbedders := &cobra.Command{
 		Use:   "embedders",
 		Short: "get embedders",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get embedders {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get embedders {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetEmbedders()
+			res, err := sess.Client().Index(args[0]).GetEmbedders()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			if res != nil {
-				fmt.Printf("Embedders: %+v\n", res)
+				sess.render(res, func() {
+					fmt.Printf("Embedders: %+v\n", res)
+				})
 			}
+			return nil
 		},
 	}
 
 	getSearchCutoffMs := &cobra.Command{
 		Use:   "search-cutoff-ms",
 		Short: "get search cutoff ms",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings get search-cutoff-ms {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings get search-cutoff-ms {uid}'")
 			}
 
-			res, err := client.Index(args[0]).GetSearchCutoffMs()
+			res, err := sess.Client().Index(args[0]).GetSearchCutoffMs()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			fmt.Printf("Search cutoff ms: %d\n", res)
+			sess.render(res, func() {
+				fmt.Printf("Search cutoff ms: %d\n", res)
+			})
+			return nil
 		},
 	}
 
@@ -580,285 +756,251 @@ func indexSettingsCmd(idxCmd *cobra.Command) *cobra.Command {
 	get.AddCommand(getEmbedders)
 	get.AddCommand(getSearchCutoffMs)
 
-	update := &cobra.Command{
-		Use:   "update",
-		Short: "update settings",
-		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) == 0 {
-				color.Red("index uid is require 'index settings update {uid}'")
-				return
-			}
+	update := settingsUpdateCmd(sess)
 
-			color.Cyan("currently not support update settings")
-		},
-	}
+	wait := false
+	waitTimeout := time.Duration(0)
 
 	reset := &cobra.Command{
 		Use:   "reset",
 		Short: "reset settings",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetSettings()
+			res, err := sess.Client().Index(args[0]).ResetSettings()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetRankingRules := &cobra.Command{
 		Use:   "ranking-rules",
 		Short: "reset ranking rules",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset ranking-rules {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset ranking-rules {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetRankingRules()
+			res, err := sess.Client().Index(args[0]).ResetRankingRules()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetDistinctAttribute := &cobra.Command{
 		Use:   "distinct-attribute",
 		Short: "reset distinct attribute",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset distinct-attribute {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset distinct-attribute {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetDistinctAttribute()
+			res, err := sess.Client().Index(args[0]).ResetDistinctAttribute()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetSearchableAttributes := &cobra.Command{
 		Use:   "searchable-attributes",
 		Short: "reset searchable attributes",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset searchable-attributes {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset searchable-attributes {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetSearchableAttributes()
+			res, err := sess.Client().Index(args[0]).ResetSearchableAttributes()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetDisplayedAttributes := &cobra.Command{
 		Use:   "displayed-attributes",
 		Short: "reset displayed attributes",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset displayed-attributes {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset displayed-attributes {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetDisplayedAttributes()
+			res, err := sess.Client().Index(args[0]).ResetDisplayedAttributes()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetStopWords := &cobra.Command{
 		Use:   "stop-words",
 		Short: "reset stop words",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset stop-words {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset stop-words {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetStopWords()
+			res, err := sess.Client().Index(args[0]).ResetStopWords()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetSynonyms := &cobra.Command{
 		Use:   "synonyms",
 		Short: "reset synonyms",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset synonyms {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset synonyms {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetSynonyms()
+			res, err := sess.Client().Index(args[0]).ResetSynonyms()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetFilterableAttributes := &cobra.Command{
 		Use:   "filterable-attributes",
 		Short: "reset filterable attributes",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset filterable-attributes {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset filterable-attributes {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetFilterableAttributes()
+			res, err := sess.Client().Index(args[0]).ResetFilterableAttributes()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetSortableAttributes := &cobra.Command{
 		Use:   "sortable-attributes",
 		Short: "reset sortable attributes",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset sortable-attributes {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset sortable-attributes {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetSortableAttributes()
+			res, err := sess.Client().Index(args[0]).ResetSortableAttributes()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetTypoTolerance := &cobra.Command{
 		Use:   "typo-tolerance",
 		Short: "reset typo-tolerance",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset typo-tolerance {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset typo-tolerance {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetTypoTolerance()
+			res, err := sess.Client().Index(args[0]).ResetTypoTolerance()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetPagination := &cobra.Command{
 		Use:   "pagination",
 		Short: "reset pagination",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset pagination {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset pagination {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetPagination()
+			res, err := sess.Client().Index(args[0]).ResetPagination()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetFaceting := &cobra.Command{
 		Use:   "faceting",
 		Short: "reset faceting",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset faceting {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset faceting {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetFaceting()
+			res, err := sess.Client().Index(args[0]).ResetFaceting()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetEmbedders := &cobra.Command{
 		Use:   "embedders",
 		Short: "reset embedders",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset embedders {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset embedders {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetEmbedders()
+			res, err := sess.Client().Index(args[0]).ResetEmbedders()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
 	resetSearchCutoffMs := &cobra.Command{
 		Use:   "search-cutoff-ms",
 		Short: "reset search cutoff ms",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("index uid is require 'index settings reset search-cutoff-ms {uid}'")
-				return
+				return usageErrorf("index uid is require 'index settings reset search-cutoff-ms {uid}'")
 			}
 
-			res, err := client.Index(args[0]).ResetSearchCutoffMs()
+			res, err := sess.Client().Index(args[0]).ResetSearchCutoffMs()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout)
 		},
 	}
 
+	addWaitFlags(reset.PersistentFlags(), &wait, &waitTimeout)
+
 	reset.AddCommand(resetRankingRules)
 	reset.AddCommand(resetDistinctAttribute)
 	reset.AddCommand(resetSearchableAttributes)
@@ -876,46 +1018,291 @@ func indexSettingsCmd(idxCmd *cobra.Command) *cobra.Command {
 	settings.AddCommand(get)
 	settings.AddCommand(update)
 	settings.AddCommand(reset)
+	settings.AddCommand(settingsDiffCmd(sess))
+	settings.AddCommand(settingsApplyCmd(sess))
+
+	setValidArgs(sess.completeIndexUID, settings.Commands()...)
 
 	idxCmd.AddCommand(settings)
 	return settings
 }
 
-func multiSearchCmd() *cobra.Command {
+func multiSearchCmd(sess *Session) *cobra.Command {
+	file := ""
+	queries := make([]string, 0)
+
 	ms := &cobra.Command{
 		Use:   "multi-search",
 		Short: "do multi search",
-		Run: func(cmd *cobra.Command, args []string) {
-			color.Cyan("TODO: how to get multiple search queries?")
+		Long: `https://www.meilisearch.com/docs/reference/api/multi_search
+
+multi-search --file queries.json
+multi-search --query movies:"star wars" --query "books:dune:year > 1960"
+cat queries.ndjson | multi-search --file -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reqs := make([]*meilisearch.SearchRequest, 0)
+
+			for _, q := range queries {
+				parts := strings.SplitN(q, ":", 3)
+				req := &meilisearch.SearchRequest{IndexUID: parts[0]}
+				if len(parts) > 1 {
+					req.Query = parts[1]
+				}
+				if len(parts) > 2 {
+					req.Filter = parts[2]
+				}
+				reqs = append(reqs, req)
+			}
+
+			var r io.Reader
+			switch {
+			case file == "-":
+				r = os.Stdin
+			case len(file) != 0:
+				f, err := os.Open(file)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+
+			if r != nil {
+				fromFile, err := decodeSearchQueries(r)
+				if err != nil {
+					return err
+				}
+				reqs = append(reqs, fromFile...)
+			}
+
+			if len(reqs) == 0 {
+				return usageErrorf("search queries is require, please see --help")
+			}
+
+			res, err := sess.Client().MultiSearch(&meilisearch.MultiSearchRequest{Queries: reqs})
+			if err != nil {
+				return err
+			}
+
+			summary := make([]map[string]interface{}, 0, len(res.Results))
+			for _, result := range res.Results {
+				summary = append(summary, map[string]interface{}{
+					"indexUid":           result.IndexUID,
+					"hits":               len(result.Hits),
+					"estimatedTotalHits": result.EstimatedTotalHits,
+					"processingTimeMs":   result.ProcessingTimeMs,
+				})
+			}
+
+			sess.renderWith(res, summary, func() {
+				for _, result := range res.Results {
+					color.Cyan("Index UID: %s", result.IndexUID)
+					fmt.Printf("Hits: %d\n", len(result.Hits))
+					printSearchResponse(&result)
+					fmt.Println()
+				}
+			}, "indexUid", "hits", "estimatedTotalHits", "processingTimeMs")
+			return nil
 		},
 	}
 
+	ms.Flags().StringVar(&file, "file", "",
+		"read queries from a JSON array, a {\"queries\": [...]} object or NDJSON file, - for stdin")
+	ms.Flags().StringArrayVar(&queries, "query", nil, "add a query as index:q[:filter], can be repeated")
+
 	return ms
 }
 
-func searchCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "search",
-		Short: "search index",
-		Long:  "https://www.meilisearch.com/docs/reference/api/search",
-		Run: func(cmd *cobra.Command, args []string) {
-			color.Cyan("currently not support search index in shell")
+// decodeSearchQueries reads search queries given as a JSON array, a multi search
+// request body or newline delimited JSON objects.
+func decodeSearchQueries(r io.Reader) ([]*meilisearch.SearchRequest, error) {
+	reqs := make([]*meilisearch.SearchRequest, 0)
+	dec := json.NewDecoder(r)
+
+	for {
+		raw := json.RawMessage{}
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		probe := struct {
+			Queries json.RawMessage `json:"queries"`
+		}{}
+		_ = json.Unmarshal(raw, &probe)
+
+		switch {
+		case bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")):
+			batch := make([]*meilisearch.SearchRequest, 0)
+			if err := json.Unmarshal(raw, &batch); err != nil {
+				return nil, err
+			}
+			reqs = append(reqs, batch...)
+		case len(probe.Queries) != 0:
+			body := new(meilisearch.MultiSearchRequest)
+			if err := json.Unmarshal(raw, body); err != nil {
+				return nil, err
+			}
+			reqs = append(reqs, body.Queries...)
+		default:
+			req := new(meilisearch.SearchRequest)
+			if err := json.Unmarshal(raw, req); err != nil {
+				return nil, err
+			}
+			reqs = append(reqs, req)
+		}
+	}
+
+	for i, req := range reqs {
+		if len(req.IndexUID) == 0 {
+			return nil, fmt.Errorf("query %d has no indexUid", i+1)
+		}
+	}
+
+	return reqs, nil
+}
+
+func searchCmd(sess *Session) *cobra.Command {
+	filter := ""
+	sortBy := make([]string, 0)
+	facets := make([]string, 0)
+	limit, offset := int64(0), int64(0)
+	page, hitsPerPage := int64(0), int64(0)
+	attributesToRetrieve := make([]string, 0)
+	attributesToHighlight := make([]string, 0)
+	attributesToCrop := make([]string, 0)
+	cropLength := int64(0)
+	matchingStrategy := ""
+	showRankingScore := false
+
+	s := &cobra.Command{
+		Use:               "search",
+		Short:             "search index",
+		ValidArgsFunction: sess.completeIndexUID,
+		Long: `https://www.meilisearch.com/docs/reference/api/search
+
+search movies "star wars" --filter "year > 2000" --sort year:desc --limit 5`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("index uid is require 'search {uid} {query}'")
+			}
+
+			req := &meilisearch.SearchRequest{
+				Limit:                 limit,
+				Offset:                offset,
+				Page:                  page,
+				HitsPerPage:           hitsPerPage,
+				Sort:                  sortBy,
+				Facets:                facets,
+				AttributesToRetrieve:  attributesToRetrieve,
+				AttributesToHighlight: attributesToHighlight,
+				AttributesToCrop:      attributesToCrop,
+				CropLength:            cropLength,
+				MatchingStrategy:      matchingStrategy,
+				ShowRankingScore:      showRankingScore,
+			}
+
+			if len(filter) != 0 {
+				req.Filter = filter
+			}
+
+			res, err := sess.Client().Index(args[0]).Search(strings.Join(args[1:], " "), req)
+			if err != nil {
+				return err
+			}
+
+			sess.renderWith(res, res.Hits, func() {
+				printSearchResponse(res)
+			})
+			return nil
 		},
 	}
+
+	s.Flags().StringVar(&filter, "filter", "", "filter queries by an attribute's value, e.g. \"genre = horror AND year > 2000\"")
+	s.Flags().StringSliceVar(&sortBy, "sort", nil, "sort search results by an attribute's value, e.g. year:desc,title:asc")
+	s.Flags().StringSliceVar(&facets, "facets", nil, "display the count of matches per facet, [*] for all facets")
+	s.Flags().Int64Var(&limit, "limit", 0, "maximum number of documents returned")
+	s.Flags().Int64Var(&offset, "offset", 0, "number of documents to skip")
+	s.Flags().Int64Var(&page, "page", 0, "request a specific page of results")
+	s.Flags().Int64Var(&hitsPerPage, "hits-per-page", 0, "maximum number of documents returned for a page")
+	s.Flags().StringSliceVar(&attributesToRetrieve, "attributes-to-retrieve", nil,
+		"attributes to display in the returned documents")
+	s.Flags().StringSliceVar(&attributesToHighlight, "attributes-to-highlight", nil,
+		"highlight matching terms contained in an attribute")
+	s.Flags().StringSliceVar(&attributesToCrop, "attributes-to-crop", nil,
+		"attributes whose values have to be cropped")
+	s.Flags().Int64Var(&cropLength, "crop-length", 0, "maximum length of cropped value in words")
+	s.Flags().StringVar(&matchingStrategy, "matching-strategy", "",
+		"strategy used to match query terms within documents (last, all)")
+	s.Flags().BoolVar(&showRankingScore, "show-ranking-score", false, "display the global ranking score of a document")
+
+	return s
 }
 
-func facetSearch() *cobra.Command {
-	return &cobra.Command{
-		Use:   "facet-search",
-		Short: "facet search index",
-		Long:  "https://www.meilisearch.com/docs/reference/api/facet_search",
-		Run: func(cmd *cobra.Command, args []string) {
-			color.Cyan("currently not support search index in shell")
+func facetSearch(sess *Session) *cobra.Command {
+	q := ""
+	filter := ""
+	matchingStrategy := ""
+	attributesToSearchOn := make([]string, 0)
+
+	fs := &cobra.Command{
+		Use:               "facet-search",
+		Short:             "facet search index",
+		ValidArgsFunction: sess.completeIndexUID,
+		Long: `https://www.meilisearch.com/docs/reference/api/facet_search
+
+facet-search movies genres act --q "star wars" --filter "year > 2000"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageErrorf("index uid and facet name is require 'facet-search {uid} {facet_name} {facet_query}'")
+			}
+
+			raw, err := sess.Client().Index(args[0]).FacetSearch(&meilisearch.FacetSearchRequest{
+				FacetName:            args[1],
+				FacetQuery:           strings.Join(args[2:], " "),
+				Q:                    q,
+				Filter:               filter,
+				MatchingStrategy:     matchingStrategy,
+				AttributesToSearchOn: attributesToSearchOn,
+			})
+			if err != nil {
+				return err
+			}
+
+			res := new(facetSearchResponse)
+			if err := json.Unmarshal(*raw, res); err != nil {
+				return err
+			}
+
+			sess.renderWith(res, res.FacetHits, func() {
+				printFacetSearchResponse(res)
+			}, "value", "count")
+			return nil
 		},
 	}
+
+	fs.Flags().StringVar(&q, "q", "", "search query used to narrow the documents the facet values are taken from")
+	fs.Flags().StringVar(&filter, "filter", "", "filter queries by an attribute's value, e.g. \"year > 2000\"")
+	fs.Flags().StringVar(&matchingStrategy, "matching-strategy", "",
+		"strategy used to match query terms within documents (last, all)")
+	fs.Flags().StringSliceVar(&attributesToSearchOn, "attributes-to-search-on", nil,
+		"restrict search to the specified attributes")
+
+	return fs
+}
+
+type facetSearchResponse struct {
+	FacetHits []struct {
+		Value string `json:"value"`
+		Count int64  `json:"count"`
+	} `json:"facetHits"`
+	FacetQuery       string `json:"facetQuery"`
+	ProcessingTimeMs int64  `json:"processingTimeMs"`
 }
 
-func KeyCmd() *cobra.Command {
+func KeyCmd(sess *Session) *cobra.Command {
 	key := &cobra.Command{
 		Use:   "key",
 		Short: "manage keys",
@@ -924,7 +1311,7 @@ func KeyCmd() *cobra.Command {
 
 	actions := make([]string, 0)
 	indexes := make([]string, 0)
-	expireAt := ""
+	expiry := &keyExpiry{}
 	uid := ""
 	name := ""
 	description := ""
@@ -932,53 +1319,61 @@ func KeyCmd() *cobra.Command {
 	create := &cobra.Command{
 		Use:   "create",
 		Short: "create one key",
-		Run: func(cmd *cobra.Command, args []string) {
+		Long: `key create --actions search,documents.get --indexes movies --expires-in 30d
+key create --actions "*" --indexes "*" --expires-at 2030-01-01T00:00:00+02:00
+key create --actions search --indexes "movies*" --no-expire`,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(actions) == 0 {
-				color.Red("key actions is require, please see --help")
-				return
+				return usageErrorf("key actions is require, please see --help")
 			}
 
-			if len(indexes) == 0 {
-				color.Red("key indexes is require, please see --help")
-				return
+			if err := checkKeyActions(actions); err != nil {
+				return err
 			}
 
-			if len(expireAt) == 0 {
-				color.Red("key expireAt is require, please see --help")
-				return
+			if len(indexes) == 0 {
+				return usageErrorf("key indexes is require, please see --help")
 			}
 
-			t, err := time.Parse("2006-01-02T15:04:05Z", expireAt)
+			t, set, err := expiry.parse(time.Now())
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
+			}
+
+			if !set {
+				return usageErrorf("key expiry is require, set --expires-at, --expires-in or --no-expire")
 			}
 
-			res, err := client.CreateKey(&meilisearch.Key{
+			// meilisearch-go formats the date as UTC without converting it
+			res, err := sess.Client().CreateKey(&meilisearch.Key{
 				Name:        name,
 				Description: description,
 				UID:         uid,
 				Actions:     actions,
 				Indexes:     indexes,
-				ExpiresAt:   t,
+				ExpiresAt:   t.UTC(),
 			})
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
+			sess.completions.invalidate(completeKeys)
 
-			printKey(res)
+			sess.printKey(res)
+			return nil
 		},
 	}
 
 	create.Flags().StringSliceVar(&actions, "actions", nil,
 		"A list of API actions permitted for the key. [*] for all actions")
+	_ = create.RegisterFlagCompletionFunc("actions", completeKeyActions)
 
 	create.Flags().StringSliceVar(&indexes, "indexes", nil,
 		"An array of indexes the key is authorized to act on. [*] for all indexes")
+	_ = create.RegisterFlagCompletionFunc("indexes", sess.completeIndexUIDs)
 
-	create.Flags().StringVar(&expireAt, "expire-at", "",
-		"Date and time when the key will expire, represented in RFC 3339 format")
+	expiry.addFlags(create.Flags())
+	create.Flags().StringVar(&expiry.at, "expire-at", "", "")
+	_ = create.Flags().MarkDeprecated("expire-at", "use --expires-at")
 
 	create.Flags().StringVar(&uid, "uid", "",
 		"A uuid v4 to identify the API key. If not specified, it is generated by Meilisearch")
@@ -992,24 +1387,33 @@ func KeyCmd() *cobra.Command {
 	list := &cobra.Command{
 		Use:   "list",
 		Short: "list all keys",
-		Run: func(cmd *cobra.Command, args []string) {
-			res, err := client.GetKeys(&meilisearch.KeysQuery{
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := sess.Client().GetKeys(&meilisearch.KeysQuery{
 				Limit:  limit,
 				Offset: offset,
 			})
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			sort.Slice(res.Results, func(i, j int) bool {
 				return i > j
 			})
 
-			for _, result := range res.Results {
-				printKey(&result)
-				lineBreaker()
+			keys := make([]*keyOutput, 0, len(res.Results))
+			rows := make([]map[string]interface{}, 0, len(res.Results))
+			for i := range res.Results {
+				keys = append(keys, newKeyOutput(&res.Results[i]))
+				rows = append(rows, keyTableRow(&res.Results[i]))
 			}
+
+			sess.renderWith(keys, rows, func() {
+				for _, result := range res.Results {
+					plainKey(&result)
+					lineBreaker()
+				}
+			}, keyColumns...)
+			return nil
 		},
 	}
 
@@ -1017,46 +1421,47 @@ func KeyCmd() *cobra.Command {
 	list.Flags().Int64Var(&offset, "offset", 0, "set offset for list of key")
 
 	get := &cobra.Command{
-		Use:   "get",
-		Short: "get one key",
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:               "get",
+		Short:             "get one key",
+		ValidArgsFunction: sess.completeKeyUID,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("key uid is require identifier (key or uid) 'key get {identifier}'")
-				return
+				return usageErrorf("key uid is require identifier (key or uid) 'key get {identifier}'")
 			}
 
-			res, err := client.GetKey(args[0])
+			res, err := sess.Client().GetKey(args[0])
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printKey(res)
+			sess.printKey(res)
+			return nil
 		},
 	}
 
 	update := &cobra.Command{
-		Use:   "update",
-		Short: "update an key",
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:               "update",
+		Short:             "update an key",
+		ValidArgsFunction: sess.completeKeyUID,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("key uid is require identifier (key or uid) 'key update {identifier}'")
-				return
+				return usageErrorf("key uid is require identifier (key or uid) 'key update {identifier}'")
 			}
 
 			identifier := args[0]
 
-			res, err := client.UpdateKey(identifier, &meilisearch.Key{
+			res, err := sess.Client().UpdateKey(identifier, &meilisearch.Key{
 				Name:        name,
 				Description: description,
 			})
 
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
+			sess.completions.invalidate(completeKeys)
 
-			printKey(res)
+			sess.printKey(res)
+			return nil
 		},
 	}
 
@@ -1065,23 +1470,26 @@ func KeyCmd() *cobra.Command {
 	update.Flags().StringVar(&description, "description", "", "set description for the key")
 
 	del := &cobra.Command{
-		Use:   "delete",
-		Short: "delete an key",
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:               "delete",
+		Short:             "delete an key",
+		ValidArgsFunction: sess.completeKeyUID,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("key uid is require identifier (key or uid) 'key delete {identifier}'")
-				return
+				return usageErrorf("key uid is require identifier (key or uid) 'key delete {identifier}'")
 			}
 
 			identifier := args[0]
 
-			res, err := client.DeleteKey(identifier)
+			res, err := sess.Client().DeleteKey(identifier)
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
+			sess.completions.invalidate(completeKeys)
 
-			fmt.Println(res)
+			sess.render(map[string]bool{"deleted": res}, func() {
+				fmt.Println(res)
+			})
+			return nil
 		},
 	}
 
@@ -1090,214 +1498,292 @@ func KeyCmd() *cobra.Command {
 	key.AddCommand(get)
 	key.AddCommand(update)
 	key.AddCommand(del)
+	key.AddCommand(tenantTokenCmd(sess))
+	key.AddCommand(keyRotateCmd(sess))
+	key.AddCommand(keyAuditCmd(sess))
 
 	return key
 }
 
-func taskCmd() *cobra.Command {
+func taskCmd(sess *Session) *cobra.Command {
 	task := &cobra.Command{
 		Use:   "task",
 		Short: "manage tasks",
 	}
 
 	get := &cobra.Command{
-		Use:   "get",
-		Short: "get a task",
-		Run: func(cmd *cobra.Command, args []string) {
+		Use:               "get",
+		Short:             "get a task",
+		ValidArgsFunction: sess.completeTaskUID,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				color.Red("task uid is require 'task get {task_uid}'")
-				return
+				return usageErrorf("task uid is require 'task get {task_uid}'")
 			}
 
-			uid, err := strconv.ParseInt(args[0], 10, 64)
+			uids, err := parseTaskUIDs(args[:1])
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			t, err := client.GetTask(uid)
+			t, err := sess.Client().GetTask(uids[0])
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTask(t)
+			sess.printTask(t)
+			return nil
 		},
 	}
 
-	list := &cobra.Command{
-		Use:   "list",
-		Short: "list all tasks",
-		Run: func(cmd *cobra.Command, args []string) {
-			// TODO we can support task list params?
-			res, err := client.GetTasks(nil)
-			if err != nil {
-				color.Red(err.Error())
-				return
-			}
-
-			sort.Slice(res.Results, func(i, j int) bool {
-				return i > j
-			})
+	wait := false
+	waitTimeout := time.Duration(0)
+	yes := false
 
-			for _, t := range res.Results {
-				printTask(&t)
-				lineBreaker()
-			}
-		},
-	}
+	cancelFilter := &taskFilter{}
 
 	cancel := &cobra.Command{
-		Use:   "cancel",
-		Short: "cancel a or many tasks",
-		Long:  "task cancel 1 2 3 4",
-		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) == 0 {
-				color.Red("task uid is require 'task cancel {task_uid or many 1 2 3 4}'")
-				return
+		Use:               "cancel",
+		Short:             "cancel a or many tasks",
+		ValidArgsFunction: sess.completeTaskUIDs,
+		Long: `task cancel 1 2 3 4
+task cancel --statuses enqueued --index-uids movies
+
+Tasks selected by filters are counted and canceled after confirmation.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			uids, err := parseTaskUIDs(args)
+			if err != nil {
+				return err
 			}
 
-			uids := make([]int64, 0)
+			cancelFilter.uids = uids
+			q, err := cancelFilter.tasksQuery()
+			if err != nil {
+				return err
+			}
 
-			for _, arg := range args {
-				uid, err := strconv.ParseInt(arg, 10, 64)
+			if !hasFilters(q) {
+				if len(uids) == 0 {
+					return usageErrorf("task uid or filter is require 'task cancel {task_uid or many 1 2 3 4}'")
+				}
+			} else {
+				count, err := sess.countTasks(*q, meilisearch.TaskStatusEnqueued, meilisearch.TaskStatusProcessing)
 				if err != nil {
-					color.Red(err.Error())
-					return
+					return err
+				}
+
+				if ok, err := confirmTasks("Cancel", count, yes); !ok {
+					return err
 				}
-				uids = append(uids, uid)
 			}
 
-			res, err := client.CancelTasks(&meilisearch.CancelTasksQuery{
-				UIDS: uids,
+			res, err := sess.Client().CancelTasks(&meilisearch.CancelTasksQuery{
+				UIDS:             q.UIDS,
+				IndexUIDS:        q.IndexUIDS,
+				Statuses:         q.Statuses,
+				Types:            q.Types,
+				BeforeEnqueuedAt: q.BeforeEnqueuedAt,
+				AfterEnqueuedAt:  q.AfterEnqueuedAt,
+				BeforeStartedAt:  q.BeforeStartedAt,
+				AfterStartedAt:   q.AfterStartedAt,
 			})
 
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
-
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout, completeTasks)
 		},
 	}
 
+	deleteFilter := &taskFilter{finished: true}
+
 	del := &cobra.Command{
-		Use:   "delete",
-		Short: "delete a or many tasks",
-		Long:  "task delete 1 2 3 4",
-		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) == 0 {
-				color.Red("task uid is require 'task cancel {task_uid or many 1 2 3 4}'")
-				return
+		Use:               "delete",
+		Short:             "delete a or many tasks",
+		ValidArgsFunction: sess.completeTaskUIDs,
+		Long: `task delete 1 2 3 4
+task delete --statuses succeeded --before-finished-at 2024-01-01
+
+Tasks selected by filters are counted and deleted after confirmation.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			uids, err := parseTaskUIDs(args)
+			if err != nil {
+				return err
 			}
 
-			uids := make([]int64, 0)
+			deleteFilter.uids = uids
+			q, err := deleteFilter.tasksQuery()
+			if err != nil {
+				return err
+			}
 
-			for _, arg := range args {
-				uid, err := strconv.ParseInt(arg, 10, 64)
+			if !hasFilters(q) {
+				if len(uids) == 0 {
+					return usageErrorf("task uid or filter is require 'task delete {task_uid or many 1 2 3 4}'")
+				}
+			} else {
+				count, err := sess.countTasks(*q, meilisearch.TaskStatusSucceeded, meilisearch.TaskStatusFailed,
+					meilisearch.TaskStatusCanceled)
 				if err != nil {
-					color.Red(err.Error())
-					return
+					return err
+				}
+
+				if ok, err := confirmTasks("Delete", count, yes); !ok {
+					return err
 				}
-				uids = append(uids, uid)
 			}
 
-			res, err := client.DeleteTasks(&meilisearch.DeleteTasksQuery{
-				UIDS: uids,
+			res, err := sess.Client().DeleteTasks(&meilisearch.DeleteTasksQuery{
+				UIDS:             q.UIDS,
+				IndexUIDS:        q.IndexUIDS,
+				Statuses:         q.Statuses,
+				Types:            q.Types,
+				CanceledBy:       q.CanceledBy,
+				BeforeEnqueuedAt: q.BeforeEnqueuedAt,
+				AfterEnqueuedAt:  q.AfterEnqueuedAt,
+				BeforeStartedAt:  q.BeforeStartedAt,
+				AfterStartedAt:   q.AfterStartedAt,
+				BeforeFinishedAt: q.BeforeFinishedAt,
+				AfterFinishedAt:  q.AfterFinishedAt,
 			})
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
-
-			printTaskInfo(res)
+			return sess.finishTask(res, wait, waitTimeout, completeTasks)
 		},
 	}
 
+	cancelFilter.addFlags(sess, cancel)
+	deleteFilter.addFlags(sess, del)
+
+	for _, c := range []*cobra.Command{cancel, del} {
+		addWaitFlags(c.Flags(), &wait, &waitTimeout)
+		c.Flags().BoolVarP(&yes, "yes", "y", false, "skip the confirmation of tasks selected by filters")
+	}
+
 	task.AddCommand(get)
-	task.AddCommand(list)
+	task.AddCommand(taskListCmd(sess))
 	task.AddCommand(cancel)
 	task.AddCommand(del)
+	task.AddCommand(taskWaitCmd(sess))
+	task.AddCommand(taskWatchCmd(sess))
 
 	return task
 }
 
-func dumpCmd() *cobra.Command {
-	return &cobra.Command{
+func dumpCmd(sess *Session) *cobra.Command {
+	wait := false
+	waitTimeout := time.Duration(0)
+
+	dump := &cobra.Command{
 		Use:   "dump",
 		Short: "create meilisearch dump",
 		Long:  "https://www.meilisearch.com/docs/reference/api/dump",
-		Run: func(cmd *cobra.Command, args []string) {
-			resp, err := client.CreateDump()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := sess.Client().CreateDump()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			printTaskInfo(resp)
+			return sess.finishTask(resp, wait, waitTimeout)
 		},
 	}
+
+	addWaitFlags(dump.Flags(), &wait, &waitTimeout)
+
+	return dump
 }
 
-func statsCmd() *cobra.Command {
+func statsCmd(sess *Session) *cobra.Command {
 	return &cobra.Command{
 		Use:   "stats",
 		Short: "stats of Meilisearch",
-		Run: func(cmd *cobra.Command, args []string) {
-			resp, err := client.GetStats()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := sess.Client().GetStats()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
 			indexes := make([]string, 0, len(resp.Indexes))
 			for key, _ := range resp.Indexes {
 				indexes = append(indexes, key)
 			}
+			sort.Strings(indexes)
+
+			rows := make([]map[string]interface{}, 0, len(indexes))
+			for _, uid := range indexes {
+				rows = append(rows, map[string]interface{}{
+					"uid":               uid,
+					"numberOfDocuments": resp.Indexes[uid].NumberOfDocuments,
+					"isIndexing":        resp.Indexes[uid].IsIndexing,
+				})
+			}
 
-			fmt.Printf(`Database Size: %s
+			sess.renderWith(resp, rows, func() {
+				fmt.Printf(`Database Size: %s
 Last Update: %s
 Indexes: %s
 `, util.FormatBytesToHumanReadable(uint64(resp.DatabaseSize)), resp.LastUpdate, strings.Join(indexes, ", "))
+			}, "uid", "numberOfDocuments", "isIndexing")
+			return nil
 		},
 	}
 }
 
-func healthCmd() *cobra.Command {
+func healthCmd(sess *Session) *cobra.Command {
 	return &cobra.Command{
 		Use:   "health",
 		Short: "check Meilisearch is healthy",
-		Run: func(cmd *cobra.Command, args []string) {
-			if ok := client.IsHealthy(); !ok {
-				color.Red("❌ Meilisearch is unhealthy")
-				return
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := sess.Client().Health()
+
+			sess.render(map[string]bool{"healthy": err == nil}, func() {
+				if err != nil {
+					color.Red("❌ Meilisearch is unhealthy")
+					return
+				}
 
-			color.Green("✅ Meilisearch is healthy")
+				color.Green("✅ Meilisearch is healthy")
+			})
+			return err
 		},
 	}
 }
 
-func versionCmd() *cobra.Command {
+func versionCmd(sess *Session) *cobra.Command {
 	return &cobra.Command{
 		Use:   "version",
 		Short: "Print the version number of Meilisearch",
-		Run: func(cmd *cobra.Command, args []string) {
-			resp, err := client.Version()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := sess.Client().Version()
 			if err != nil {
-				color.Red(err.Error())
-				return
+				return err
 			}
 
-			fmt.Printf(`Version: %s
+			sess.render(resp, func() {
+				fmt.Printf(`Version: %s
 Commit SHA: %s
 Commit Date: %s
 `, resp.PkgVersion, resp.CommitSha, resp.CommitDate)
+			})
+			return nil
 		},
 	}
 }
 
-func printTaskInfo(t *meilisearch.TaskInfo) {
+var (
+	indexColumns    = []string{"uid", "primaryKey", "createdAt", "updatedAt"}
+	taskInfoColumns = []string{"taskUid", "indexUid", "status", "type", "enqueuedAt"}
+	taskColumns     = []string{"uid", "indexUid", "status", "type", "duration", "enqueuedAt", "finishedAt"}
+	keyColumns      = []string{"uid", "name", "actions", "indexes", "expiresAt"}
+)
+
+func (s *Session) printTaskInfo(t *meilisearch.TaskInfo) {
+	s.render(t, func() {
+		plainTaskInfo(t)
+	}, taskInfoColumns...)
+}
+
+func plainTaskInfo(t *meilisearch.TaskInfo) {
 	fmt.Printf(`Task UID: %d
 Index UID: %s
 Status: %s
@@ -1307,7 +1793,13 @@ Enqueued At: %s
 
 }
 
-func printTask(t *meilisearch.Task) {
+func (s *Session) printTask(t *meilisearch.Task) {
+	s.render(t, func() {
+		plainTask(t)
+	}, taskColumns...)
+}
+
+func plainTask(t *meilisearch.Task) {
 	fmt.Printf(`Task UID: %d
 Index UID: %s
 UID: %d
@@ -1325,7 +1817,61 @@ CanceledBy: %d
 	)
 }
 
-func printKey(k *meilisearch.Key) {
+func (s *Session) printKey(k *meilisearch.Key) {
+	s.renderWith(newKeyOutput(k), keyTableRow(k), func() {
+		plainKey(k)
+	}, keyColumns...)
+}
+
+// keyOutput is a key as printed in json and yaml, the expiresAt of a key
+// without expiry is null instead of the zero time.
+type keyOutput struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Key         string     `json:"key,omitempty"`
+	UID         string     `json:"uid,omitempty"`
+	Actions     []string   `json:"actions,omitempty"`
+	Indexes     []string   `json:"indexes,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+}
+
+func newKeyOutput(k *meilisearch.Key) *keyOutput {
+	o := &keyOutput{
+		Name:        k.Name,
+		Description: k.Description,
+		Key:         k.Key,
+		UID:         k.UID,
+		Actions:     k.Actions,
+		Indexes:     k.Indexes,
+		CreatedAt:   k.CreatedAt,
+		UpdatedAt:   k.UpdatedAt,
+	}
+	if !k.ExpiresAt.IsZero() {
+		t := k.ExpiresAt
+		o.ExpiresAt = &t
+	}
+	return o
+}
+
+// keyTableRow returns the keyColumns of k, with "no expire" like the plain output.
+func keyTableRow(k *meilisearch.Key) map[string]interface{} {
+	expire := "no expire"
+	if !k.ExpiresAt.IsZero() {
+		expire = k.ExpiresAt.Format(time.RFC3339Nano)
+	}
+
+	return map[string]interface{}{
+		"uid":       k.UID,
+		"name":      k.Name,
+		"actions":   k.Actions,
+		"indexes":   k.Indexes,
+		"expiresAt": expire,
+	}
+}
+
+func plainKey(k *meilisearch.Key) {
 	expire := k.ExpiresAt.String()
 	if k.ExpiresAt.IsZero() {
 		expire = "no expire"
@@ -1344,7 +1890,13 @@ UpdatedAt: %s
 		expire, k.CreatedAt, k.UpdatedAt)
 }
 
-func printSettings(set *meilisearch.Settings) {
+func (s *Session) printSettings(set *meilisearch.Settings) {
+	s.render(set, func() {
+		plainSettings(set)
+	})
+}
+
+func plainSettings(set *meilisearch.Settings) {
 	distinctAttribute := ""
 	typoTolerance := meilisearch.TypoTolerance{}
 	pagination := meilisearch.Pagination{}
@@ -1395,59 +1947,61 @@ Embedders: %+v
 	)
 }
 
-func lineBreaker() {
-	fmt.Println("---------------------------------")
-}
+func printSearchResponse(res *meilisearch.SearchResponse) {
+	for _, hit := range res.Hits {
+		printJSON(hit)
+		lineBreaker()
+	}
 
-func connect(host, key string, cleanFunc func(), exit bool) {
-	u, err := url.Parse(host)
-	if err != nil {
-		color.Red(err.Error())
-		if exit {
-			os.Exit(1)
-		}
-		return
+	if res.TotalPages != 0 {
+		fmt.Printf("Page: %d/%d\nTotal Hits: %d\n", res.Page, res.TotalPages, res.TotalHits)
+	} else {
+		fmt.Printf("Estimated Total Hits: %d\n", res.EstimatedTotalHits)
 	}
 
-	client = meilisearch.NewClient(meilisearch.ClientConfig{
-		Host:   u.String(),
-		APIKey: key,
-	})
+	if res.FacetDistribution != nil {
+		fmt.Println("Facet Distribution:")
+		printJSON(res.FacetDistribution)
+	}
 
-	if !client.IsHealthy() {
-		color.Red("❌ Failed connect to Meilisearch, Host or API-Key is invalid")
-		if exit {
-			os.Exit(1)
-		}
-		return
+	fmt.Printf("Processing Time: %dms\n", res.ProcessingTimeMs)
+}
+
+func printFacetSearchResponse(res *facetSearchResponse) {
+	for _, hit := range res.FacetHits {
+		fmt.Printf("%s: %d\n", hit.Value, hit.Count)
 	}
 
-	ver, err := client.Version()
+	fmt.Printf("Facet Hits: %d\nProcessing Time: %dms\n", len(res.FacetHits), res.ProcessingTimeMs)
+}
+
+func printJSON(v interface{}) {
+	b, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
-		e := new(meilisearch.Error)
-		ok := errors.As(err, &e)
-		if ok {
-			if e.StatusCode == 401 || e.StatusCode == 403 {
-				color.Red("master key is invalid, 'meilishell --api-key foobar'")
-				if exit {
-					os.Exit(1)
-				}
-				return
-			}
-		}
 		color.Red(err.Error())
-		if exit {
-			os.Exit(1)
-		}
 		return
 	}
 
-	_prefix = fmt.Sprintf("Meilishell@%s > ", u.Host)
+	fmt.Println(string(b))
+}
 
-	if cleanFunc != nil {
-		cleanFunc()
+func confirm(msg string) bool {
+	fmt.Printf("%s [y/N]: ", msg)
+
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
 	}
 
+	return false
+}
+
+func lineBreaker() {
+	fmt.Println("---------------------------------")
+}
+
+func printHeader(ver *meilisearch.Version) {
 	fmt.Printf(header, version(), ver.PkgVersion, "✅ Meilisearch is healthy", time.Now().Format("2006-01-02 15:04:05"))
 	fmt.Println()
 }
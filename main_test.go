@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeSearchQueries(t *testing.T) {
+	for name, input := range map[string]string{
+		"array":  `[{"indexUid": "movies", "q": "star wars"}, {"indexUid": "books", "filter": "year > 1960"}]`,
+		"body":   `{"queries": [{"indexUid": "movies", "q": "star wars"}, {"indexUid": "books", "filter": "year > 1960"}]}`,
+		"ndjson": "{\"indexUid\": \"movies\", \"q\": \"star wars\"}\n{\"indexUid\": \"books\", \"filter\": \"year > 1960\"}\n",
+	} {
+		reqs, err := decodeSearchQueries(strings.NewReader(input))
+		require.NoError(t, err, name)
+		require.Len(t, reqs, 2, name)
+		require.Equal(t, "movies", reqs[0].IndexUID, name)
+		require.Equal(t, "star wars", reqs[0].Query, name)
+		require.Equal(t, "books", reqs[1].IndexUID, name)
+		require.Equal(t, "year > 1960", reqs[1].Filter, name)
+	}
+
+	reqs, err := decodeSearchQueries(strings.NewReader(""))
+	require.NoError(t, err)
+	require.Empty(t, reqs)
+
+	_, err = decodeSearchQueries(strings.NewReader(`{"q": "star wars"}`))
+	require.EqualError(t, err, "query 1 has no indexUid")
+
+	_, err = decodeSearchQueries(strings.NewReader(`{"indexUid": `))
+	require.Error(t, err)
+}
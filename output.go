@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputPlain = "plain"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+)
+
+const maxCellWidth = 60
+
+// render prints v in the selected output format. plain is the hand written
+// text output, columns select and order the columns of the table output.
+func (s *Session) render(v interface{}, plain func(), columns ...string) {
+	s.renderWith(v, v, plain, columns...)
+}
+
+// renderWith is like render, but builds the table output from table
+// instead of v, e.g. the hits of a search response.
+func (s *Session) renderWith(v, table interface{}, plain func(), columns ...string) {
+	switch s.outputFormat() {
+	case outputPlain:
+		plain()
+	case outputJSON:
+		printJSON(v)
+	case outputYAML:
+		printYAML(v)
+	case outputTable:
+		printTable(table, columns...)
+	default:
+		color.Red("unknown output format %q, use plain, json, yaml or table", s.outputFormat())
+	}
+}
+
+func checkOutputFormat(format string) error {
+	switch format {
+	case "", outputPlain, outputJSON, outputYAML, outputTable:
+		return nil
+	}
+
+	return usageErrorf("unknown output format %q, use plain, json, yaml or table", format)
+}
+
+func printYAML(v interface{}) {
+	generic, err := toGeneric(v)
+	if err != nil {
+		color.Red(err.Error())
+		return
+	}
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	defer enc.Close()
+
+	if err := enc.Encode(generic); err != nil {
+		color.Red(err.Error())
+	}
+}
+
+func printTable(v interface{}, columns ...string) {
+	generic, err := toGeneric(v)
+	if err != nil {
+		color.Red(err.Error())
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	switch t := generic.(type) {
+	case []interface{}:
+		rows := make([]map[string]interface{}, 0, len(t))
+		for _, item := range t {
+			row, ok := item.(map[string]interface{})
+			if !ok {
+				row = map[string]interface{}{"value": item}
+			}
+			rows = append(rows, row)
+		}
+
+		if len(columns) == 0 {
+			columns = tableColumns(rows)
+		}
+
+		writeTableRow(w, tableHeader(columns))
+		for _, row := range rows {
+			cells := make([]string, 0, len(columns))
+			for _, col := range columns {
+				cells = append(cells, tableCell(row[col]))
+			}
+			writeTableRow(w, cells)
+		}
+	case map[string]interface{}:
+		if len(columns) == 0 {
+			columns = tableColumns([]map[string]interface{}{t})
+		}
+
+		writeTableRow(w, []string{"FIELD", "VALUE"})
+		for _, col := range columns {
+			writeTableRow(w, []string{col, tableCell(t[col])})
+		}
+	default:
+		writeTableRow(w, []string{"VALUE"})
+		writeTableRow(w, []string{tableCell(t)})
+	}
+}
+
+// toGeneric converts v to maps and slices through its JSON encoding, so every
+// format uses the field names of the Meilisearch API.
+func toGeneric(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+
+	return generic, nil
+}
+
+func tableColumns(rows []map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	columns := make([]string, 0)
+
+	for _, row := range rows {
+		for key := range row {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	return columns
+}
+
+func tableHeader(columns []string) []string {
+	header := make([]string, 0, len(columns))
+	for _, col := range columns {
+		header = append(header, strings.ToUpper(col))
+	}
+
+	return header
+}
+
+func tableCell(v interface{}) string {
+	s := ""
+	switch t := v.(type) {
+	case nil:
+	case string:
+		s = t
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			s = fmt.Sprintf("%v", t)
+		} else {
+			s = string(b)
+		}
+	}
+
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > maxCellWidth {
+		s = string(r[:maxCellWidth-3]) + "..."
+	}
+
+	return s
+}
+
+func writeTableRow(w *tabwriter.Writer, cells []string) {
+	fmt.Fprintln(w, strings.Join(cells, "\t"))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableCell(t *testing.T) {
+	require.Equal(t, "", tableCell(nil))
+	require.Equal(t, "movies", tableCell("movies"))
+	require.Equal(t, "42", tableCell(42.0))
+	require.Equal(t, `["search","documents.add"]`, tableCell([]interface{}{"search", "documents.add"}))
+	require.Equal(t, "multi line", tableCell("multi\nline"))
+
+	long := tableCell(strings.Repeat("a", 100))
+	require.Len(t, long, maxCellWidth)
+	require.True(t, strings.HasSuffix(long, "..."))
+}
+
+func TestTableColumns(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"uid": "movies", "primaryKey": "id"},
+		{"uid": "books", "createdAt": "2024-01-01"},
+	}
+
+	require.Equal(t, []string{"createdAt", "primaryKey", "uid"}, tableColumns(rows))
+}
+
+func TestOutputFormat(t *testing.T) {
+	sess := NewSession(nil)
+	require.Equal(t, outputPlain, sess.outputFormat())
+
+	sess.outputFlag = outputJSON
+	require.Equal(t, outputJSON, sess.outputFormat())
+}
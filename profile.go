@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Ja7ad/meilishell/config"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+func loadConfig() (*config.Config, error) {
+	path, err := config.DefaultPath()
+	if err != nil {
+		path = ""
+	}
+
+	return config.Load(path)
+}
+
+func profileCmd(sess *Session) *cobra.Command {
+	profile := &cobra.Command{
+		Use:   "profile",
+		Short: "manage connection profiles",
+		Long:  "profiles are stored in ~/.config/meilishell/config.yaml",
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "list of profiles",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := sess.conf.Names()
+			if len(names) == 0 {
+				color.Cyan("no profile found, add one with 'profile add {name} {host}'")
+				return nil
+			}
+
+			for _, name := range names {
+				printProfile(name, sess.conf.Profiles[name], name == sess.conf.Current)
+				lineBreaker()
+			}
+			return nil
+		},
+	}
+
+	key := ""
+	timeout := time.Duration(0)
+	insecure := false
+	caCert, cert, certKey := "", "", ""
+
+	add := &cobra.Command{
+		Use:   "add",
+		Short: "add or replace a profile",
+		Long: `profile add staging https://staging.example.com --api-key foobar --timeout 10s
+profile add prod https://prod.example.com --api-key foobar --ca-cert ca.pem --cert client.pem --cert-key client-key.pem`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageErrorf("profile name and host is require 'profile add {name} {host}'")
+			}
+
+			p := &config.Profile{
+				Host:    args[1],
+				APIKey:  key,
+				Timeout: timeout,
+			}
+
+			if insecure || len(caCert) != 0 || len(cert) != 0 || len(certKey) != 0 {
+				p.TLS = &config.TLS{
+					InsecureSkipVerify: insecure,
+					CACert:             caCert,
+					Cert:               cert,
+					Key:                certKey,
+				}
+			}
+
+			if err := sess.conf.Add(args[0], p); err != nil {
+				return err
+			}
+
+			if err := sess.conf.Save(); err != nil {
+				return err
+			}
+
+			color.Green("profile %s saved to %s", args[0], sess.conf.Path())
+			return nil
+		},
+	}
+
+	add.Flags().StringVar(&key, "api-key", "", "set meilisearch api key or master key "+
+		"(https://www.meilisearch.com/docs/reference/api/keys)")
+	add.Flags().DurationVar(&timeout, "timeout", 0, "set request timeout, e.g. 10s")
+	add.Flags().BoolVar(&insecure, "insecure-skip-verify", false, "skip verification of the server certificate")
+	add.Flags().StringVar(&caCert, "ca-cert", "", "path of a PEM encoded CA certificate to verify the server")
+	add.Flags().StringVar(&cert, "cert", "", "path of a PEM encoded client certificate")
+	add.Flags().StringVar(&certKey, "cert-key", "", "path of a PEM encoded client certificate key")
+
+	remove := &cobra.Command{
+		Use:   "remove",
+		Short: "remove a profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("profile name is require 'profile remove {name}'")
+			}
+
+			if err := sess.conf.Remove(args[0]); err != nil {
+				return err
+			}
+
+			if err := sess.conf.Save(); err != nil {
+				return err
+			}
+
+			color.Green("profile %s removed", args[0])
+			return nil
+		},
+	}
+
+	use := &cobra.Command{
+		Use:   "use",
+		Short: "set the default profile and connect to it",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("profile name is require 'profile use {name}'")
+			}
+
+			if err := sess.conf.Use(args[0]); err != nil {
+				return err
+			}
+
+			if err := sess.conf.Save(); err != nil {
+				return err
+			}
+
+			p, _ := sess.conf.Profile(args[0])
+			ver, err := sess.Connect(p)
+			if err != nil {
+				return err
+			}
+
+			printHeader(ver)
+			return nil
+		},
+	}
+
+	profile.AddCommand(list)
+	profile.AddCommand(add)
+	profile.AddCommand(remove)
+	profile.AddCommand(use)
+
+	return profile
+}
+
+func printProfile(name string, p *config.Profile, current bool) {
+	if current {
+		name += " (current)"
+	}
+
+	timeout := "default"
+	if p.Timeout != 0 {
+		timeout = p.Timeout.String()
+	}
+
+	apiKey := "not set"
+	if len(p.APIKey) != 0 {
+		apiKey = "set"
+	}
+
+	tls := "default"
+	if p.TLS != nil {
+		tls = fmt.Sprintf("insecure-skip-verify=%t ca-cert=%s cert=%s", p.TLS.InsecureSkipVerify, p.TLS.CACert, p.TLS.Cert)
+	}
+
+	fmt.Printf(`Name: %s
+Host: %s
+API Key: %s
+Timeout: %s
+TLS: %s
+`, name, p.Host, apiKey, timeout, tls)
+}
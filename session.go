@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/Ja7ad/meilishell/config"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/valyala/fasthttp"
+)
+
+// Session holds the connection to Meilisearch and the output settings shared
+// by the commands, which get it from their constructor.
+type Session struct {
+	conn atomic.Pointer[connection]
+	conf *config.Config
+
+	// output is set once for the whole session, outputFlag per command.
+	output     string
+	outputFlag string
+
+	completions *completionCache
+}
+
+// connection is replaced as a whole by Connect, so a command never sees the
+// client of one server with the host of another.
+type connection struct {
+	client *meilisearch.Client
+	http   *fasthttp.Client
+	host   string
+	apiKey string
+	prefix string
+
+	timeout time.Duration
+}
+
+func NewSession(conf *config.Config) *Session {
+	s := &Session{
+		conf:        conf,
+		output:      outputPlain,
+		completions: &completionCache{entries: make(map[string]completionEntry)},
+	}
+	s.completions.finished = s.taskFinished
+
+	return s
+}
+
+// Client returns the client of the current connection, nil before Connect.
+func (s *Session) Client() *meilisearch.Client {
+	if c := s.conn.Load(); c != nil {
+		return c.client
+	}
+	return nil
+}
+
+func (s *Session) Host() string {
+	if c := s.conn.Load(); c != nil {
+		return c.host
+	}
+	return ""
+}
+
+func (s *Session) APIKey() string {
+	if c := s.conn.Load(); c != nil {
+		return c.apiKey
+	}
+	return ""
+}
+
+// Connect connects to the Meilisearch of the profile and returns the version
+// of the server. The current connection is kept when the server is not reachable.
+func (s *Session) Connect(p *config.Profile) (*meilisearch.Version, error) {
+	u, err := url.Parse(p.Host)
+	if err != nil {
+		return nil, usageErrorf("invalid host %q: %s", p.Host, err.Error())
+	}
+
+	tlsConfig, err := p.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := meilisearch.ClientConfig{
+		Host:    u.String(),
+		APIKey:  p.APIKey,
+		Timeout: p.Timeout,
+	}
+
+	// the http client is shared with the requests the client cannot send
+	httpClient := &fasthttp.Client{
+		Name:             "meilishell",
+		ConnPoolStrategy: fasthttp.LIFO,
+		TLSConfig:        tlsConfig,
+	}
+	client := meilisearch.NewFastHTTPCustomClient(cfg, httpClient)
+
+	// version requires a valid key, unlike health
+	ver, err := client.Version()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	s.conn.Store(&connection{
+		client:  client,
+		http:    httpClient,
+		host:    u.String(),
+		apiKey:  p.APIKey,
+		prefix:  fmt.Sprintf("Meilishell@%s > ", u.Host),
+		timeout: p.Timeout,
+	})
+	s.completions.invalidate()
+
+	return ver, nil
+}
+
+func (s *Session) livePrefix() (string, bool) {
+	if c := s.conn.Load(); c != nil {
+		return c.prefix, true
+	}
+	return "", true
+}
+
+func (s *Session) outputFormat() string {
+	if len(s.outputFlag) != 0 {
+		return s.outputFlag
+	}
+
+	return s.output
+}
+
+// rawRequest sends body to the endpoint of the current connection and returns
+// the response body, for the requests the client can not send without typing
+// the JSON documents. Errors are the ones of the client, see wrapError.
+func (s *Session) rawRequest(function, method, endpoint string, body []byte, accepted int) ([]byte, error) {
+	c := s.conn.Load()
+	if c == nil {
+		return nil, fmt.Errorf("not connected to Meilisearch")
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	res := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(res)
+
+	req.SetRequestURI(c.host + endpoint)
+	req.Header.SetMethod(method)
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	req.Header.Set("User-Agent", meilisearch.GetQualifiedVersion())
+	if body != nil {
+		req.Header.SetContentType("application/json")
+		req.SetBody(body)
+	}
+
+	me := &meilisearch.Error{
+		Endpoint:           endpoint,
+		Method:             method,
+		Function:           function,
+		RequestToString:    string(body),
+		StatusCodeExpected: []int{accepted},
+	}
+
+	var err error
+	if c.timeout != 0 {
+		err = c.http.DoTimeout(req, res, c.timeout)
+	} else {
+		err = c.http.Do(req, res)
+	}
+	switch {
+	case errors.Is(err, fasthttp.ErrTimeout):
+		return nil, me.WithErrCode(meilisearch.MeilisearchTimeoutError, err)
+	case err != nil:
+		return nil, me.WithErrCode(meilisearch.MeilisearchCommunicationError, err)
+	}
+
+	me.StatusCode = res.StatusCode()
+	if me.StatusCode != accepted {
+		me.ErrorBody(res.Body())
+		if len(me.MeilisearchApiError.Code) == 0 {
+			return nil, me.WithErrCode(meilisearch.MeilisearchApiErrorWithoutMessage)
+		}
+		return nil, me.WithErrCode(meilisearch.MeilisearchApiError)
+	}
+
+	// the body is released with the response
+	return append([]byte(nil), res.Body()...), nil
+}
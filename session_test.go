@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Ja7ad/meilishell/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_ConnectFailureKeepsConnection(t *testing.T) {
+	sess := NewSession(nil)
+	require.Nil(t, sess.Client())
+
+	_, err := sess.Connect(&config.Profile{Host: "http://127.0.0.1:1"})
+	require.Error(t, err)
+	require.Equal(t, exitNetwork, exitCode(err))
+
+	require.Nil(t, sess.Client())
+	require.Empty(t, sess.Host())
+
+	prefix, _ := sess.livePrefix()
+	require.Empty(t, prefix)
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func settingsUpdateCmd(sess *Session) *cobra.Command {
+	file := ""
+	wait := false
+	waitTimeout := time.Duration(0)
+
+	update := &cobra.Command{
+		Use:   "update",
+		Short: "update settings",
+		Long: `https://www.meilisearch.com/docs/reference/api/settings#update-settings
+
+index settings update movies --file settings.yaml --wait
+cat settings.json | index settings update movies --file -
+index settings update filterable-attributes movies genre year`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("index uid is require 'index settings update {uid} --file {file}'")
+			}
+
+			set, err := readSettings(file)
+			if err != nil {
+				return err
+			}
+
+			res, err := sess.updateSettingsJSON(args[0], "", set)
+			if err != nil {
+				return err
+			}
+
+			return sess.finishTask(res, wait, waitTimeout)
+		},
+	}
+
+	update.Flags().StringVar(&file, "file", "", "settings file in JSON or YAML format, - for stdin")
+	addWaitFlags(update.PersistentFlags(), &wait, &waitTimeout)
+
+	printResult := func(res *meilisearch.TaskInfo) error {
+		return sess.finishTask(res, wait, waitTimeout)
+	}
+
+	update.AddCommand(updateStringsSettingCmd(sess, "ranking-rules", "update ranking rules", printResult,
+		func(idx *meilisearch.Index, v []string) (*meilisearch.TaskInfo, error) {
+			return idx.UpdateRankingRules(&v)
+		}))
+
+	update.AddCommand(&cobra.Command{
+		Use:   "distinct-attribute",
+		Short: "update distinct attribute",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageErrorf("index uid and attribute is require 'index settings update distinct-attribute {uid} {attribute}'")
+			}
+
+			res, err := sess.Client().Index(args[0]).UpdateDistinctAttribute(args[1])
+			if err != nil {
+				return err
+			}
+
+			return printResult(res)
+		},
+	})
+
+	update.AddCommand(updateStringsSettingCmd(sess, "searchable-attributes", "update searchable attributes", printResult,
+		func(idx *meilisearch.Index, v []string) (*meilisearch.TaskInfo, error) {
+			return idx.UpdateSearchableAttributes(&v)
+		}))
+
+	update.AddCommand(updateStringsSettingCmd(sess, "displayed-attributes", "update displayed attributes", printResult,
+		func(idx *meilisearch.Index, v []string) (*meilisearch.TaskInfo, error) {
+			return idx.UpdateDisplayedAttributes(&v)
+		}))
+
+	update.AddCommand(updateStringsSettingCmd(sess, "stop-words", "update stop words", printResult,
+		func(idx *meilisearch.Index, v []string) (*meilisearch.TaskInfo, error) {
+			return idx.UpdateStopWords(&v)
+		}))
+
+	update.AddCommand(&cobra.Command{
+		Use:   "synonyms",
+		Short: "update synonyms",
+		Long:  "index settings update synonyms movies wolverine=xmen,logan logan=wolverine",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageErrorf("index uid and synonyms is require 'index settings update synonyms {uid} {word=synonym,synonym}'")
+			}
+
+			synonyms := make(map[string][]string)
+			for _, arg := range args[1:] {
+				word, values, ok := strings.Cut(arg, "=")
+				if !ok {
+					return usageErrorf("invalid synonym %q, expected word=synonym,synonym", arg)
+				}
+				synonyms[word] = strings.Split(values, ",")
+			}
+
+			res, err := sess.Client().Index(args[0]).UpdateSynonyms(&synonyms)
+			if err != nil {
+				return err
+			}
+
+			return printResult(res)
+		},
+	})
+
+	update.AddCommand(updateStringsSettingCmd(sess, "filterable-attributes", "update filterable attributes", printResult,
+		func(idx *meilisearch.Index, v []string) (*meilisearch.TaskInfo, error) {
+			return idx.UpdateFilterableAttributes(&v)
+		}))
+
+	update.AddCommand(updateStringsSettingCmd(sess, "sortable-attributes", "update sortable attributes", printResult,
+		func(idx *meilisearch.Index, v []string) (*meilisearch.TaskInfo, error) {
+			return idx.UpdateSortableAttributes(&v)
+		}))
+
+	update.AddCommand(&cobra.Command{
+		Use:   "typo-tolerance",
+		Short: "update typo-tolerance",
+		Long:  `index settings update typo-tolerance movies '{"enabled": true, "disableOnWords": ["shrek"]}'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageErrorf("index uid and typo tolerance is require 'index settings update typo-tolerance {uid} {json}'")
+			}
+
+			typo := []byte(strings.Join(args[1:], " "))
+			if err := json.Unmarshal(typo, new(meilisearch.TypoTolerance)); err != nil {
+				return usageErrorf("invalid typo tolerance: %s", err.Error())
+			}
+
+			res, err := sess.updateSettingsJSON(args[0], "typo-tolerance", typo)
+			if err != nil {
+				return err
+			}
+
+			return printResult(res)
+		},
+	})
+
+	update.AddCommand(updateIntSettingCmd(sess, "pagination", "update pagination max total hits", printResult,
+		func(idx *meilisearch.Index, v int64) (*meilisearch.TaskInfo, error) {
+			return idx.UpdatePagination(&meilisearch.Pagination{MaxTotalHits: v})
+		}))
+
+	update.AddCommand(updateIntSettingCmd(sess, "faceting", "update faceting max values per facet", printResult,
+		func(idx *meilisearch.Index, v int64) (*meilisearch.TaskInfo, error) {
+			return idx.UpdateFaceting(&meilisearch.Faceting{MaxValuesPerFacet: v})
+		}))
+
+	update.AddCommand(&cobra.Command{
+		Use:   "embedders",
+		Short: "update embedders",
+		Long:  `index settings update embedders movies '{"default": {"source": "userProvided", "dimensions": 512}}'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageErrorf("index uid and embedders is require 'index settings update embedders {uid} {json}'")
+			}
+
+			embedders := []byte(strings.Join(args[1:], " "))
+			if err := json.Unmarshal(embedders, new(map[string]meilisearch.Embedder)); err != nil {
+				return usageErrorf("invalid embedders: %s", err.Error())
+			}
+
+			res, err := sess.updateSettingsJSON(args[0], "embedders", embedders)
+			if err != nil {
+				return err
+			}
+
+			return printResult(res)
+		},
+	})
+
+	update.AddCommand(updateIntSettingCmd(sess, "search-cutoff-ms", "update search cutoff ms", printResult,
+		func(idx *meilisearch.Index, v int64) (*meilisearch.TaskInfo, error) {
+			return idx.UpdateSearchCutoffMs(v)
+		}))
+
+	return update
+}
+
+// updateSettingsJSON sends the settings document b to the settings route of
+// index uid, or to one of its sub-routes like typo-tolerance. The client types
+// settings as structs, which drops the fields it does not know and the zero
+// values it omits, and adds the fields it always sends: a partial typo
+// tolerance document would get "enabled": false.
+func (s *Session) updateSettingsJSON(uid, route string, b []byte) (*meilisearch.TaskInfo, error) {
+	endpoint := "/indexes/" + url.PathEscape(uid) + "/settings"
+	if len(route) != 0 {
+		endpoint += "/" + route
+	}
+
+	return s.settingsTask("UpdateSettings", http.MethodPatch, endpoint, b)
+}
+
+// resetSettingJSON resets the setting field of index uid through its route,
+// including the settings the client has no reset method for.
+func (s *Session) resetSettingJSON(uid, field string) (*meilisearch.TaskInfo, error) {
+	endpoint := "/indexes/" + url.PathEscape(uid) + "/settings/" + settingRoute(field)
+	return s.settingsTask("ResetSettings", http.MethodDelete, endpoint, nil)
+}
+
+func (s *Session) settingsTask(function, method, endpoint string, b []byte) (*meilisearch.TaskInfo, error) {
+	body, err := s.rawRequest(function, method, endpoint, b, http.StatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+
+	info := new(meilisearch.TaskInfo)
+	if err := json.Unmarshal(body, info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// settingRoute turns a setting field into its route, e.g. rankingRules into ranking-rules.
+func settingRoute(field string) string {
+	var b strings.Builder
+	for _, r := range field {
+		if unicode.IsUpper(r) {
+			b.WriteByte('-')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func updateStringsSettingCmd(sess *Session, use, short string, printResult func(*meilisearch.TaskInfo) error,
+	fn func(idx *meilisearch.Index, v []string) (*meilisearch.TaskInfo, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long:  fmt.Sprintf("index settings update %s movies foo bar baz", use),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageErrorf("index uid and values is require 'index settings update %s {uid} {value...}'", use)
+			}
+
+			res, err := fn(sess.Client().Index(args[0]), args[1:])
+			if err != nil {
+				return err
+			}
+
+			return printResult(res)
+		},
+	}
+}
+
+func updateIntSettingCmd(sess *Session, use, short string, printResult func(*meilisearch.TaskInfo) error,
+	fn func(idx *meilisearch.Index, v int64) (*meilisearch.TaskInfo, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long:  fmt.Sprintf("index settings update %s movies 1000", use),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageErrorf("index uid and value is require 'index settings update %s {uid} {value}'", use)
+			}
+
+			v, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return usageErrorf("invalid %s %q, expected a number", use, args[1])
+			}
+
+			res, err := fn(sess.Client().Index(args[0]), v)
+			if err != nil {
+				return err
+			}
+
+			return printResult(res)
+		},
+	}
+}
+
+// readSettings reads a full or partial settings document in JSON or YAML
+// format from file, or from stdin when file is "-", and returns it as JSON.
+// The fields known by the client are checked against the settings type, but
+// the document is kept as written, so no field is lost on the way.
+func readSettings(file string) ([]byte, error) {
+	b, err := readSettingsFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	j, err := settingsToJSON(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := decodeSettings(j); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+func readSettingsFile(file string) ([]byte, error) {
+	var r io.Reader
+	switch {
+	case file == "-":
+		r = os.Stdin
+	case len(file) != 0:
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	default:
+		return nil, usageErrorf("settings file is require, use --file {file} or --file - for stdin")
+	}
+
+	return io.ReadAll(r)
+}
+
+func decodeSettings(b []byte) (*meilisearch.Settings, error) {
+	j, err := settingsToJSON(b)
+	if err != nil {
+		return nil, err
+	}
+
+	// the fields the client does not know are left to Meilisearch, which
+	// knows more settings than the client does and rejects misspelled ones
+	set := new(meilisearch.Settings)
+	if err := json.Unmarshal(j, set); err != nil {
+		return nil, usageErrorf("invalid settings: %s", err.Error())
+	}
+
+	return set, nil
+}
+
+// settingsToJSON converts YAML documents to JSON so the keys match the
+// camelCase names of the Meilisearch API.
+func settingsToJSON(b []byte) ([]byte, error) {
+	if json.Valid(b) {
+		return b, nil
+	}
+
+	raw := make(map[string]interface{})
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("settings is neither valid JSON nor YAML: %w", err)
+	}
+
+	return json.Marshal(raw)
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+type changeKind string
+
+const (
+	changeAdded   changeKind = "+"
+	changeRemoved changeKind = "-"
+	changeUpdated changeKind = "~"
+)
+
+type settingChange struct {
+	kind  changeKind
+	path  string
+	value interface{}
+	old   interface{}
+}
+
+func settingsDiffCmd(sess *Session) *cobra.Command {
+	file := ""
+
+	diff := &cobra.Command{
+		Use:   "diff",
+		Short: "show the changes between current and desired settings",
+		Long:  "index settings diff movies --file desired.yaml",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("index uid is require 'index settings diff {uid} --file {file}'")
+			}
+
+			changes, _, err := planSettings(sess, args[0], file)
+			if err != nil {
+				return err
+			}
+
+			printSettingsPlan(changes)
+			return nil
+		},
+	}
+
+	diff.Flags().StringVar(&file, "file", "", "desired settings file in JSON or YAML format, - for stdin")
+
+	return diff
+}
+
+func settingsApplyCmd(sess *Session) *cobra.Command {
+	file := ""
+	yes := false
+	wait := false
+	waitTimeout := time.Duration(0)
+
+	apply := &cobra.Command{
+		Use:   "apply",
+		Short: "apply only the changed settings after confirmation",
+		Long:  "index settings apply movies --file desired.yaml --wait",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("index uid is require 'index settings apply {uid} --file {file}'")
+			}
+
+			changes, desired, err := planSettings(sess, args[0], file)
+			if err != nil {
+				return err
+			}
+
+			printSettingsPlan(changes)
+			if len(changes) == 0 {
+				return nil
+			}
+
+			if !yes {
+				if !term.IsTerminal(int(os.Stdin.Fd())) {
+					return usageErrorf("applying %d setting changes requires confirmation, use --yes", len(changes))
+				}
+
+				if !confirm("Apply these changes?") {
+					color.Yellow("apply canceled")
+					return nil
+				}
+			}
+
+			update := make(map[string]interface{})
+			resets := make([]string, 0)
+
+			for _, field := range changedFields(changes) {
+				if isEmptySetting(desired[field]) {
+					resets = append(resets, field)
+					continue
+				}
+				update[field] = desired[field]
+			}
+
+			if len(update) != 0 {
+				// the values are sent as written in the file, see updateSettingsJSON
+				b, err := json.Marshal(update)
+				if err != nil {
+					return err
+				}
+
+				res, err := sess.updateSettingsJSON(args[0], "", b)
+				if err != nil {
+					return err
+				}
+
+				if err := sess.finishTask(res, wait, waitTimeout); err != nil {
+					return err
+				}
+			}
+
+			for _, field := range resets {
+				res, err := resetSetting(sess, args[0], field)
+				if err != nil {
+					return err
+				}
+
+				lineBreaker()
+				if err := sess.finishTask(res, wait, waitTimeout); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	apply.Flags().StringVar(&file, "file", "", "desired settings file in JSON or YAML format, - for stdin")
+	apply.Flags().BoolVarP(&yes, "yes", "y", false, "apply without confirmation")
+	addWaitFlags(apply.Flags(), &wait, &waitTimeout)
+
+	return apply
+}
+
+// planSettings compares the settings of index uid with the desired settings file.
+// Only the fields present in the file are compared, so a partial file only
+// manages the settings it mentions.
+func planSettings(sess *Session, uid, file string) ([]settingChange, map[string]interface{}, error) {
+	b, err := readSettingsFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// validate the document against the settings type first
+	if _, err := decodeSettings(b); err != nil {
+		return nil, nil, err
+	}
+
+	j, err := settingsToJSON(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	desired := make(map[string]interface{})
+	if err := json.Unmarshal(j, &desired); err != nil {
+		return nil, nil, err
+	}
+
+	// the current settings are read as the server returns them, the client
+	// types leave out the settings it does not know
+	b, err = sess.rawRequest("GetSettings", http.MethodGet, "/indexes/"+url.PathEscape(uid)+"/settings", nil, http.StatusOK)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := make(map[string]interface{})
+	if err := json.Unmarshal(b, &current); err != nil {
+		return nil, nil, err
+	}
+
+	return diffSettings(current, desired), desired, nil
+}
+
+func diffSettings(current, desired map[string]interface{}) []settingChange {
+	fields := make([]string, 0, len(desired))
+	for field := range desired {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	changes := make([]settingChange, 0)
+	for _, field := range fields {
+		// synonyms are replaced as a whole, other objects are merged
+		changes = append(changes, diffValue(field, current[field], desired[field], field == "synonyms")...)
+	}
+
+	return changes
+}
+
+func diffValue(path string, old, value interface{}, replace bool) []settingChange {
+	if reflect.DeepEqual(old, value) || (isEmptySetting(old) && isEmptySetting(value)) {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		o, _ := old.([]interface{})
+		changes := make([]settingChange, 0)
+
+		for _, item := range v {
+			if !containsValue(o, item) {
+				changes = append(changes, settingChange{kind: changeAdded, path: path, value: item})
+			}
+		}
+
+		for _, item := range o {
+			if !containsValue(v, item) {
+				changes = append(changes, settingChange{kind: changeRemoved, path: path, value: item})
+			}
+		}
+
+		if len(changes) == 0 {
+			changes = append(changes, settingChange{kind: changeUpdated, path: path + " (order)", old: old, value: value})
+		}
+
+		return changes
+	case map[string]interface{}:
+		o, _ := old.(map[string]interface{})
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		changes := make([]settingChange, 0)
+		for _, key := range keys {
+			if _, ok := o[key]; !ok {
+				changes = append(changes, settingChange{kind: changeAdded, path: path + "." + key, value: v[key]})
+				continue
+			}
+			changes = append(changes, diffValue(path+"."+key, o[key], v[key], false)...)
+		}
+
+		if replace {
+			removed := make([]string, 0)
+			for key := range o {
+				if _, ok := v[key]; !ok {
+					removed = append(removed, key)
+				}
+			}
+			sort.Strings(removed)
+
+			for _, key := range removed {
+				changes = append(changes, settingChange{kind: changeRemoved, path: path + "." + key, value: o[key]})
+			}
+		}
+
+		return changes
+	default:
+		return []settingChange{{kind: changeUpdated, path: path, old: old, value: value}}
+	}
+}
+
+func changedFields(changes []settingChange) []string {
+	fields := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, c := range changes {
+		field, _, _ := strings.Cut(c.path, ".")
+		field, _, _ = strings.Cut(field, " ")
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+func printSettingsPlan(changes []settingChange) {
+	if len(changes) == 0 {
+		color.Green("settings are up to date, no changes")
+		return
+	}
+
+	for _, c := range changes {
+		switch c.kind {
+		case changeAdded:
+			color.Green("+ %s: %s", c.path, settingValue(c.value))
+		case changeRemoved:
+			color.Red("- %s: %s", c.path, settingValue(c.value))
+		default:
+			color.Yellow("~ %s: %s -> %s", c.path, settingValue(c.old), settingValue(c.value))
+		}
+	}
+
+	fmt.Printf("\nPlan: %d change(s) in %s\n", len(changes), strings.Join(changedFields(changes), ", "))
+}
+
+func settingValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return string(b)
+}
+
+func containsValue(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isEmptySetting(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	}
+
+	return false
+}
+
+func resetSetting(sess *Session, uid, field string) (*meilisearch.TaskInfo, error) {
+	idx := sess.Client().Index(uid)
+	switch field {
+	case "rankingRules":
+		return idx.ResetRankingRules()
+	case "distinctAttribute":
+		return idx.ResetDistinctAttribute()
+	case "searchableAttributes":
+		return idx.ResetSearchableAttributes()
+	case "displayedAttributes":
+		return idx.ResetDisplayedAttributes()
+	case "stopWords":
+		return idx.ResetStopWords()
+	case "synonyms":
+		return idx.ResetSynonyms()
+	case "filterableAttributes":
+		return idx.ResetFilterableAttributes()
+	case "sortableAttributes":
+		return idx.ResetSortableAttributes()
+	case "typoTolerance":
+		return idx.ResetTypoTolerance()
+	case "pagination":
+		return idx.ResetPagination()
+	case "faceting":
+		return idx.ResetFaceting()
+	case "embedders":
+		return idx.ResetEmbedders()
+	case "searchCutoffMs":
+		return idx.ResetSearchCutoffMs()
+	default:
+		return sess.resetSettingJSON(uid, field)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSettings_NoChanges(t *testing.T) {
+	current := map[string]interface{}{
+		"rankingRules": []interface{}{"words", "typo"},
+		"stopWords":    []interface{}{"the"},
+	}
+	desired := map[string]interface{}{
+		"rankingRules": []interface{}{"words", "typo"},
+	}
+
+	require.Empty(t, diffSettings(current, desired))
+}
+
+func TestDiffSettings_List(t *testing.T) {
+	current := map[string]interface{}{"filterableAttributes": []interface{}{"genre", "year"}}
+	desired := map[string]interface{}{"filterableAttributes": []interface{}{"genre", "director"}}
+
+	expected := []settingChange{
+		{kind: changeAdded, path: "filterableAttributes", value: "director"},
+		{kind: changeRemoved, path: "filterableAttributes", value: "year"},
+	}
+	require.Equal(t, expected, diffSettings(current, desired))
+}
+
+func TestDiffSettings_Order(t *testing.T) {
+	current := map[string]interface{}{"rankingRules": []interface{}{"words", "typo"}}
+	desired := map[string]interface{}{"rankingRules": []interface{}{"typo", "words"}}
+
+	changes := diffSettings(current, desired)
+	require.Len(t, changes, 1)
+	require.Equal(t, changeUpdated, changes[0].kind)
+	require.Equal(t, []string{"rankingRules"}, changedFields(changes))
+}
+
+func TestDiffSettings_NestedObject(t *testing.T) {
+	current := map[string]interface{}{
+		"typoTolerance": map[string]interface{}{
+			"enabled":             true,
+			"minWordSizeForTypos": map[string]interface{}{"oneTypo": 5.0, "twoTypos": 9.0},
+		},
+	}
+	desired := map[string]interface{}{
+		"typoTolerance": map[string]interface{}{
+			"minWordSizeForTypos": map[string]interface{}{"oneTypo": 4.0},
+		},
+	}
+
+	expected := []settingChange{
+		{kind: changeUpdated, path: "typoTolerance.minWordSizeForTypos.oneTypo", old: 5.0, value: 4.0},
+	}
+	require.Equal(t, expected, diffSettings(current, desired))
+}
+
+func TestDiffSettings_SynonymsReplaced(t *testing.T) {
+	current := map[string]interface{}{
+		"synonyms": map[string]interface{}{"logan": []interface{}{"wolverine"}},
+	}
+	desired := map[string]interface{}{
+		"synonyms": map[string]interface{}{"hulk": []interface{}{"banner"}},
+	}
+
+	expected := []settingChange{
+		{kind: changeAdded, path: "synonyms.hulk", value: []interface{}{"banner"}},
+		{kind: changeRemoved, path: "synonyms.logan", value: []interface{}{"wolverine"}},
+	}
+	require.Equal(t, expected, diffSettings(current, desired))
+}
+
+func TestSettingsApplyCmd(t *testing.T) {
+	f, sess := connectFake(t)
+
+	file := filepath.Join(t.TempDir(), "desired.yaml")
+	require.NoError(t, os.WriteFile(file,
+		[]byte("typoTolerance:\n  enabled: false\nstopWords: [the]\nproximityPrecision: byAttribute\n"), 0o600))
+
+	// stdin is not a terminal in tests
+	out := runLines(t, newRootCmd(sess), "index settings apply movies --file "+file)
+	require.Contains(t, out, "requires confirmation, use --yes (exit 2)")
+
+	out = runLines(t, newRootCmd(sess), "index settings apply movies --file "+file+" --yes --wait")
+	require.NotContains(t, out, "error:")
+	require.Contains(t, out, "proximityPrecision")
+
+	require.Equal(t, map[string]interface{}{
+		"typoTolerance":      map[string]interface{}{"enabled": false},
+		"stopWords":          []interface{}{"the"},
+		"proximityPrecision": "byAttribute",
+	}, f.body(t, "PATCH", "/indexes/movies/settings"))
+	require.Equal(t, false, f.indexes["movies"].settings["typoTolerance"].(map[string]interface{})["enabled"])
+	require.Equal(t, "byAttribute", f.indexes["movies"].settings["proximityPrecision"])
+
+	// the settings unknown by the client are compared and reset too
+	f.indexes["movies"].settings["separatorTokens"] = []interface{}{"&"}
+	require.NoError(t, os.WriteFile(file, []byte("proximityPrecision: byAttribute\nseparatorTokens: []\n"), 0o600))
+	out = runLines(t, newRootCmd(sess), "index settings apply movies --file "+file+" --yes --wait")
+	require.NotContains(t, out, "error:")
+	require.Contains(t, out, "1 change(s) in separatorTokens")
+	require.Equal(t, []interface{}{}, f.indexes["movies"].settings["separatorTokens"])
+
+	// misspelled settings are rejected by Meilisearch
+	require.NoError(t, os.WriteFile(file, []byte("stopWord: [the]\n"), 0o600))
+	out = runLines(t, newRootCmd(sess), "index settings apply movies --file "+file+" --yes")
+	require.Contains(t, out, "Unknown field `stopWord`")
+	require.Contains(t, out, "(exit 5)")
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const typoSettingsYAML = `
+typoTolerance:
+  enabled: false
+  disableOnWords: [shrek]
+pagination:
+  maxTotalHits: 0
+`
+
+func TestReadSettings_File(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "settings.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(typoSettingsYAML), 0o600))
+
+	b, err := readSettings(file)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"typoTolerance": {"enabled": false, "disableOnWords": ["shrek"]}, "pagination": {"maxTotalHits": 0}}`,
+		string(b))
+
+	_, err = readSettings(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(file, []byte(`{"stopWords": "the"}`), 0o600))
+	_, err = readSettings(file)
+	require.Error(t, err)
+}
+
+func TestReadSettings_Stdin(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"typoTolerance": {"enabled": false}}`), 0o600))
+
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	// a piped stdin is only read with --file -
+	_, err = readSettings("")
+	require.Equal(t, exitUsage, exitCode(err))
+
+	b, err := readSettings("-")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"typoTolerance": {"enabled": false}}`, string(b))
+}
+
+func TestSettingsUpdateCmd_File(t *testing.T) {
+	f, sess := connectFake(t)
+
+	file := filepath.Join(t.TempDir(), "settings.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(typoSettingsYAML), 0o600))
+
+	out := runLines(t, newRootCmd(sess), "index settings update movies --file "+file+" --wait")
+	require.NotContains(t, out, "error:")
+
+	require.Equal(t, map[string]interface{}{
+		"typoTolerance": map[string]interface{}{"enabled": false, "disableOnWords": []interface{}{"shrek"}},
+		"pagination":    map[string]interface{}{"maxTotalHits": float64(0)},
+	}, f.body(t, "PATCH", "/indexes/movies/settings"))
+	require.Equal(t, false, f.indexes["movies"].settings["typoTolerance"].(map[string]interface{})["enabled"])
+}
+
+func TestSettingsUpdateCmd_TypoTolerance(t *testing.T) {
+	f, sess := connectFake(t)
+
+	out := runLines(t, newRootCmd(sess),
+		`index settings update typo-tolerance movies '{"enabled": false, "minWordSizeForTypos": {"oneTypo": 0}}'`,
+		`index settings update typo-tolerance movies '{"enabled": "no"}'`,
+	)
+	require.Contains(t, out, "error: invalid typo tolerance")
+	require.Contains(t, out, "(exit 2)")
+
+	require.Equal(t, map[string]interface{}{
+		"enabled":             false,
+		"minWordSizeForTypos": map[string]interface{}{"oneTypo": float64(0)},
+	}, f.body(t, "PATCH", "/indexes/movies/settings/typo-tolerance"))
+}
+
+func TestSettingsUpdateCmd_Embedders(t *testing.T) {
+	f, sess := connectFake(t)
+
+	out := runLines(t, newRootCmd(sess),
+		`index settings update embedders movies '{"default": {"source": "rest", "url": "http://localhost:8080", "dimensions": 3}}'`,
+		`index settings update embedders movies '{"default": '`,
+		`index settings update pagination movies many`,
+	)
+	require.Contains(t, out, "error: invalid embedders")
+	require.Contains(t, out, `error: invalid pagination "many", expected a number (exit 2)`)
+
+	// the fields the client does not know are sent too
+	require.Equal(t, map[string]interface{}{
+		"default": map[string]interface{}{"source": "rest", "url": "http://localhost:8080", "dimensions": float64(3)},
+	}, f.body(t, "PATCH", "/indexes/movies/settings/embedders"))
+}
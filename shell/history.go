@@ -0,0 +1,145 @@
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const redactedSecret = "***"
+
+var secretFlag = regexp.MustCompile(`(--api-key(?:=|\s+))("[^"]*"|'[^']*'|\S+)`)
+
+// History keeps the executed lines of the shell and persists them to a file
+// so they are available across sessions. Lines are kept as typed in memory,
+// so !n runs them again, and the secrets are redacted in the file.
+type History struct {
+	path  string
+	limit int
+	lines []string
+}
+
+// NewHistory loads the history stored at path, keeping at most limit lines.
+// A missing file is not an error.
+func NewHistory(path string, limit int) (*History, error) {
+	h := &History{
+		path:  path,
+		limit: limit,
+		lines: make([]string, 0),
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return h, nil
+		}
+		return h, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			h.lines = append(h.lines, line)
+		}
+	}
+	h.trim()
+
+	return h, scanner.Err()
+}
+
+// Lines returns the history entries with redacted secrets, oldest first.
+func (h *History) Lines() []string {
+	lines := make([]string, len(h.lines))
+	for i, line := range h.lines {
+		lines[i] = Redact(line)
+	}
+	return lines
+}
+
+// Recall returns the entries that can run again from the prompt, oldest
+// first. Entries loaded from the file lost their secrets and are left out.
+func (h *History) Recall() []string {
+	lines := make([]string, 0, len(h.lines))
+	for _, line := range h.lines {
+		if !redacted(line) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Add appends line to the history and saves the file.
+func (h *History) Add(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+
+	h.lines = append(h.lines, line)
+	h.trim()
+
+	return h.save()
+}
+
+// Expand resolves "!!" to the last entry and "!n" to the nth entry of the history.
+// Entries loaded from the file lost their secrets and are not expanded.
+func (h *History) Expand(line string) (string, error) {
+	ref := strings.TrimSpace(line)
+	if !strings.HasPrefix(ref, "!") {
+		return line, nil
+	}
+
+	if len(h.lines) == 0 {
+		return "", errors.New("history is empty")
+	}
+
+	n := len(h.lines)
+	if ref != "!!" {
+		var err error
+		n, err = strconv.Atoi(ref[1:])
+		if err != nil || n < 1 || n > len(h.lines) {
+			return "", fmt.Errorf("%s: event not found", ref)
+		}
+	}
+
+	entry := h.lines[n-1]
+	if redacted(entry) {
+		return "", fmt.Errorf("%s: the secret of the entry was not saved, type the command again", ref)
+	}
+
+	return entry, nil
+}
+
+func (h *History) trim() {
+	if h.limit > 0 && len(h.lines) > h.limit {
+		h.lines = h.lines[len(h.lines)-h.limit:]
+	}
+}
+
+func (h *History) save() error {
+	if h.path == "" {
+		return nil
+	}
+
+	return os.WriteFile(h.path, []byte(strings.Join(h.Lines(), "\n")+"\n"), 0o600)
+}
+
+// Redact hides the values of secret flags such as --api-key.
+func Redact(line string) string {
+	return secretFlag.ReplaceAllString(line, "${1}"+redactedSecret)
+}
+
+// redacted reports whether a secret of line was replaced by Redact.
+func redacted(line string) bool {
+	for _, m := range secretFlag.FindAllStringSubmatch(line, -1) {
+		if m[2] == redactedSecret {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	require.Equal(t, "connect http://localhost:7700 --api-key ***",
+		Redact("connect http://localhost:7700 --api-key foobar"))
+	require.Equal(t, "connect --api-key=*** http://localhost:7700",
+		Redact("connect --api-key=foobar http://localhost:7700"))
+	require.Equal(t, `connect --api-key *** x`, Redact(`connect --api-key "foo bar" x`))
+	require.Equal(t, "index list", Redact("index list"))
+}
+
+func TestHistory_AddAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	h, err := NewHistory(path, 2)
+	require.NoError(t, err)
+	require.Empty(t, h.Lines())
+
+	require.NoError(t, h.Add("index list"))
+	require.NoError(t, h.Add(""))
+	require.NoError(t, h.Add("connect http://localhost:7700 --api-key foobar"))
+	require.NoError(t, h.Add("task list"))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "foobar")
+
+	h, err = NewHistory(path, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"connect http://localhost:7700 --api-key ***", "task list"}, h.Lines())
+}
+
+func TestHistory_Expand(t *testing.T) {
+	h := &History{lines: []string{"index list", "task list"}}
+
+	line, err := h.Expand("!1")
+	require.NoError(t, err)
+	require.Equal(t, "index list", line)
+
+	line, err = h.Expand("!!")
+	require.NoError(t, err)
+	require.Equal(t, "task list", line)
+
+	line, err = h.Expand("stats")
+	require.NoError(t, err)
+	require.Equal(t, "stats", line)
+
+	_, err = h.Expand("!3")
+	require.Error(t, err)
+}
+
+func TestHistory_ExpandSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	h, err := NewHistory(path, 10)
+	require.NoError(t, err)
+	require.NoError(t, h.Add("connect http://localhost:7700 --api-key foobar"))
+
+	// the secret is kept for this session only
+	line, err := h.Expand("!!")
+	require.NoError(t, err)
+	require.Equal(t, "connect http://localhost:7700 --api-key foobar", line)
+	require.Equal(t, []string{"connect http://localhost:7700 --api-key ***"}, h.Lines())
+
+	h, err = NewHistory(path, 10)
+	require.NoError(t, err)
+
+	_, err = h.Expand("!1")
+	require.ErrorContains(t, err, "secret of the entry was not saved")
+}
+
+func TestHistory_Recall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	h, err := NewHistory(path, 10)
+	require.NoError(t, err)
+	require.NoError(t, h.Add("connect http://localhost:7700 --api-key foobar"))
+	require.NoError(t, h.Add("task list"))
+	require.Equal(t, []string{"connect http://localhost:7700 --api-key foobar", "task list"}, h.Recall())
+
+	// the redacted entries would run with *** as the secret
+	h, err = NewHistory(path, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"task list"}, h.Recall())
+}
+
+func TestEditCommandTree_AddHistory(t *testing.T) {
+	root := &cobra.Command{}
+
+	s := &lexer{root: root, history: &History{}}
+	s.editCommandTree(nil)
+	require.True(t, hasSubcommand(root, "history"))
+}
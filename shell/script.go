@@ -0,0 +1,85 @@
+package shell
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/shlex"
+	"github.com/spf13/cobra"
+)
+
+// LineError is the error of a failed script line.
+type LineError struct {
+	Line int
+	Text string
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Text, e.Err.Error())
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Exec runs a single command line against the root command without a prompt.
+// The command gets an empty stdin, so it neither reads the lines of a script
+// piped on stdin nor waits for an answer nobody types.
+func Exec(root *cobra.Command, line string) error {
+	args, err := shlex.Split(line)
+	if err != nil {
+		return err
+	}
+
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		return err
+	}
+	defer null.Close()
+
+	stdin := os.Stdin
+	os.Stdin = null
+	defer func() { os.Stdin = stdin }()
+
+	return execute(root, args)
+}
+
+// RunScript runs every line of r against the root command, skipping blank lines and
+// lines starting with "#". onError is called with a *LineError for every failed line
+// when it is not nil. It stops at the first failing line unless keepGoing is set,
+// in which case the first error is returned once all lines ran.
+func RunScript(root *cobra.Command, r io.Reader, keepGoing bool, onError func(error)) error {
+	var first error
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if err := Exec(root, line); err != nil {
+			err = &LineError{Line: n, Text: line, Err: err}
+			if onError != nil {
+				onError(err)
+			}
+
+			if !keepGoing {
+				return err
+			}
+			if first == nil {
+				first = err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return first
+}
@@ -0,0 +1,110 @@
+package shell
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func newScriptRoot(ran *[]string) *cobra.Command {
+	root := &cobra.Command{Use: "root", SilenceErrors: true, SilenceUsage: true}
+
+	root.AddCommand(&cobra.Command{
+		Use: "ok",
+		Run: func(_ *cobra.Command, args []string) {
+			*ran = append(*ran, "ok "+strings.Join(args, " "))
+		},
+	})
+
+	root.AddCommand(&cobra.Command{
+		Use: "fail",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			*ran = append(*ran, "fail")
+			return errors.New("failed")
+		},
+	})
+
+	return root
+}
+
+func TestExec(t *testing.T) {
+	ran := make([]string, 0)
+	root := newScriptRoot(&ran)
+
+	require.NoError(t, Exec(root, `ok "a b"`))
+	require.Error(t, Exec(root, "fail"))
+	require.Equal(t, []string{"ok a b", "fail"}, ran)
+}
+
+func TestRunScript_EmptyStdin(t *testing.T) {
+	ran := make([]string, 0)
+	root := newScriptRoot(&ran)
+	root.AddCommand(&cobra.Command{
+		Use: "read",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			b, err := io.ReadAll(os.Stdin)
+			ran = append(ran, "read "+string(b))
+			return err
+		},
+	})
+
+	file := filepath.Join(t.TempDir(), "script")
+	require.NoError(t, os.WriteFile(file, []byte("read\nok 1\n"), 0o600))
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	// the script is piped on stdin, the command must not read its next lines
+	require.NoError(t, RunScript(root, os.Stdin, false, nil))
+	require.Equal(t, []string{"read ", "ok 1"}, ran)
+	require.Equal(t, f, os.Stdin)
+}
+
+func TestRunScript_SkipCommentsAndBlankLines(t *testing.T) {
+	ran := make([]string, 0)
+	root := newScriptRoot(&ran)
+
+	script := `# comment
+
+ok 1
+ok 2
+`
+	require.NoError(t, RunScript(root, strings.NewReader(script), false, nil))
+	require.Equal(t, []string{"ok 1", "ok 2"}, ran)
+}
+
+func TestRunScript_StopOnError(t *testing.T) {
+	ran := make([]string, 0)
+	root := newScriptRoot(&ran)
+
+	err := RunScript(root, strings.NewReader("ok 1\nfail\nok 2\n"), false, nil)
+	require.ErrorContains(t, err, "line 2")
+	require.Equal(t, []string{"ok 1", "fail"}, ran)
+}
+
+func TestRunScript_KeepGoing(t *testing.T) {
+	ran := make([]string, 0)
+	root := newScriptRoot(&ran)
+
+	failed := make([]error, 0)
+	err := RunScript(root, strings.NewReader("fail\nok 2\nfail\n"), true, func(err error) {
+		failed = append(failed, err)
+	})
+	require.Equal(t, []string{"fail", "ok 2", "fail"}, ran)
+	require.Len(t, failed, 2)
+
+	var le *LineError
+	require.ErrorAs(t, err, &le)
+	require.Equal(t, 1, le.Line)
+	require.Equal(t, "fail", le.Text)
+}
@@ -19,29 +19,50 @@ type lexer struct {
 	refresh func() *cobra.Command
 	cache   map[string][]prompt.Suggest
 	stdin   *term.State
+	history *History
+	onError func(error)
+
+	// exited is set by the exit command, which ends the prompt once the line ran
+	exited bool
 }
 
 // New creates a Cobra CLI command named "shell" which runs an interactive shell prompt for the root command.
-func New(root *cobra.Command, refresh func() *cobra.Command, opts ...prompt.Option) *cobra.Command {
+// Executed lines are recorded to history when it is not nil, and onError is called with the error of
+// every failed line when it is not nil. The command returns once exit, quit or Ctrl-D ends the prompt,
+// with the terminal restored, so callers can run their shutdown hooks after it.
+func New(root *cobra.Command, refresh func() *cobra.Command, history *History, onError func(error),
+	opts ...prompt.Option) *cobra.Command {
 	sh := &lexer{
 		root:    root,
 		refresh: refresh,
 		cache:   make(map[string][]prompt.Suggest),
+		history: history,
+		onError: onError,
 	}
 
 	prefix := fmt.Sprintf("> %s ", root.Name())
-	opts = append(opts, prompt.OptionPrefix(prefix), prompt.OptionShowCompletionAtStart())
+	opts = append(opts, prompt.OptionPrefix(prefix), prompt.OptionShowCompletionAtStart(),
+		prompt.OptionSetExitCheckerOnInput(sh.exitChecker))
+
+	if history != nil {
+		opts = append(opts, prompt.OptionHistory(history.Recall()))
+	}
 
 	return &cobra.Command{
 		Use:   "shell",
 		Short: "Start an interactive shell.",
 		Run: func(cmd *cobra.Command, _ []string) {
 			sh.saveStdin()
+			defer sh.restoreStdin()
 
+			sh.exited = false
 			sh.editCommandTree(cmd)
 			prompt.New(sh.executor, sh.completer, opts...).Run()
 
-			sh.restoreStdin()
+			// go-prompt already rendered the next prompt when exit ran
+			if sh.exited {
+				fmt.Println()
+			}
 		},
 	}
 }
@@ -56,17 +77,40 @@ func (s *lexer) editCommandTree(shell *cobra.Command) {
 	}
 
 	s.root.AddCommand(&cobra.Command{
-		Use:   "exit",
-		Short: "Exit the interactive shell.",
+		Use:     "exit",
+		Aliases: []string{"quit"},
+		Short:   "Exit the interactive shell.",
 		Run: func(*cobra.Command, []string) {
-			// TODO: Exit cleanly without help from the os package
-			os.Exit(0)
+			s.exited = true
 		},
 	})
 
+	if s.history != nil {
+		s.root.AddCommand(&cobra.Command{
+			Use:   "history",
+			Short: "Show the command history.",
+			Long: `history [search]
+
+Use !n to execute the nth entry again and !! to execute the last one.`,
+			Run: func(cmd *cobra.Command, args []string) {
+				search := strings.Join(args, " ")
+				for i, line := range s.history.Lines() {
+					if strings.Contains(line, search) {
+						cmd.Printf("%5d  %s\n", i+1, line)
+					}
+				}
+			},
+		})
+	}
+
 	initDefaultHelpFlag(s.root)
 }
 
+// exitChecker ends the prompt after the line running the exit command.
+func (s *lexer) exitChecker(_ string, breakline bool) bool {
+	return breakline && s.exited
+}
+
 func initDefaultHelpFlag(cmd *cobra.Command) {
 	cmd.InitDefaultHelpFlag()
 
@@ -87,8 +131,25 @@ func (s *lexer) executor(line string) {
 	// Allow command to read from stdin
 	s.restoreStdin()
 
+	if s.history != nil {
+		expanded, err := s.history.Expand(line)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		if expanded != line {
+			fmt.Println(Redact(expanded))
+		}
+
+		line = expanded
+		_ = s.history.Add(line)
+	}
+
 	args, _ := shlex.Split(line)
-	_ = execute(s.root, args)
+	if err := execute(s.root, args); err != nil && s.onError != nil {
+		s.onError(err)
+	}
 
 	if s.refresh != nil {
 		s.root = s.refresh()
@@ -172,6 +233,7 @@ func execute(cmd *cobra.Command, args []string) error {
 			} else {
 				_ = flag.Value.Set(flag.DefValue)
 			}
+			flag.Changed = false
 
 			_ = c.Flags().SetAnnotation(flag.Name, cobra.BashCompOneRequiredFlag, []string{"false"})
 		})
@@ -235,7 +297,7 @@ func escapeSpecialCharacters(val string) string {
 	}
 
 	if strings.ContainsAny(val, " #&*;<>?[]|~") {
-		val = fmt.Sprintf(`"%q"`, val)
+		val = fmt.Sprintf(`"%s"`, val)
 	}
 
 	return val
@@ -139,6 +139,18 @@ func TestEditCommandTree_AddExit(t *testing.T) {
 	require.True(t, hasSubcommand(root, "exit"))
 }
 
+func TestEditCommandTree_ExitEndsPrompt(t *testing.T) {
+	root := &cobra.Command{}
+
+	s := &lexer{root: root}
+	s.editCommandTree(nil)
+	require.False(t, s.exitChecker("version", true))
+
+	require.NoError(t, execute(root, []string{"quit"}))
+	require.False(t, s.exitChecker("quit", false))
+	require.True(t, s.exitChecker("quit", true))
+}
+
 func hasSubcommand(cmd *cobra.Command, name string) bool {
 	for _, subcommand := range cmd.Commands() {
 		if subcommand.Name() == name {
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// allTasksPageSize is the page size of task list --all when --limit is not set.
+const allTasksPageSize = 1000
+
+var (
+	taskStatuses = []string{
+		string(meilisearch.TaskStatusEnqueued),
+		string(meilisearch.TaskStatusProcessing),
+		string(meilisearch.TaskStatusSucceeded),
+		string(meilisearch.TaskStatusFailed),
+		string(meilisearch.TaskStatusCanceled),
+	}
+
+	taskTypes = []string{
+		string(meilisearch.TaskTypeIndexCreation),
+		string(meilisearch.TaskTypeIndexUpdate),
+		string(meilisearch.TaskTypeIndexDeletion),
+		string(meilisearch.TaskTypeIndexSwap),
+		string(meilisearch.TaskTypeDocumentAdditionOrUpdate),
+		string(meilisearch.TaskTypeDocumentDeletion),
+		string(meilisearch.TaskTypeSettingsUpdate),
+		string(meilisearch.TaskTypeDumpCreation),
+		string(meilisearch.TaskTypeTaskCancelation),
+		string(meilisearch.TaskTypeTaskDeletion),
+		string(meilisearch.TaskTypeSnapshotCreation),
+	}
+)
+
+// taskFilter holds the task filters shared by task list, cancel, delete and watch.
+type taskFilter struct {
+	uids             []int64
+	indexUIDs        []string
+	statuses         []string
+	types            []string
+	canceledBy       []int64
+	beforeEnqueuedAt string
+	afterEnqueuedAt  string
+	beforeStartedAt  string
+	afterStartedAt   string
+	beforeFinishedAt string
+	afterFinishedAt  string
+
+	// finished is false for task cancel, which can not filter on finished tasks
+	finished bool
+}
+
+func (f *taskFilter) addFlags(sess *Session, cmd *cobra.Command) {
+	flags := cmd.Flags()
+
+	flags.StringSliceVar(&f.indexUIDs, "index-uids", nil, "filter by index uids, e.g. movies,books")
+	flags.StringSliceVar(&f.statuses, "statuses", nil, "filter by statuses, e.g. enqueued,processing")
+	flags.StringSliceVar(&f.types, "types", nil, "filter by types, e.g. documentAdditionOrUpdate")
+	flags.StringVar(&f.beforeEnqueuedAt, "before-enqueued-at", "", "filter tasks enqueued before a date, e.g. 2024-01-31")
+	flags.StringVar(&f.afterEnqueuedAt, "after-enqueued-at", "", "filter tasks enqueued after a date")
+	flags.StringVar(&f.beforeStartedAt, "before-started-at", "", "filter tasks started before a date")
+	flags.StringVar(&f.afterStartedAt, "after-started-at", "", "filter tasks started after a date")
+
+	if f.finished {
+		flags.Int64SliceVar(&f.canceledBy, "canceled-by", nil, "filter by uids of the canceling tasks")
+		flags.StringVar(&f.beforeFinishedAt, "before-finished-at", "", "filter tasks finished before a date")
+		flags.StringVar(&f.afterFinishedAt, "after-finished-at", "", "filter tasks finished after a date")
+	}
+
+	_ = cmd.RegisterFlagCompletionFunc("index-uids", sess.completeIndexUIDs)
+	_ = cmd.RegisterFlagCompletionFunc("statuses", cobra.FixedCompletions(taskStatuses, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("types", cobra.FixedCompletions(taskTypes, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// addUIDsFlag adds --uids, for commands which do not take task uids as arguments.
+func (f *taskFilter) addUIDsFlag(flags *pflag.FlagSet) {
+	flags.Int64SliceVar(&f.uids, "uids", nil, "filter by task uids, e.g. 1,2,3")
+}
+
+func (f *taskFilter) tasksQuery() (*meilisearch.TasksQuery, error) {
+	q := &meilisearch.TasksQuery{
+		UIDS:       f.uids,
+		IndexUIDS:  f.indexUIDs,
+		CanceledBy: f.canceledBy,
+	}
+
+	for _, s := range f.statuses {
+		if !slices.Contains(taskStatuses, s) {
+			return nil, usageErrorf("unknown task status %q, use one of %s", s, strings.Join(taskStatuses, ", "))
+		}
+		q.Statuses = append(q.Statuses, meilisearch.TaskStatus(s))
+	}
+
+	for _, t := range f.types {
+		if !slices.Contains(taskTypes, t) {
+			return nil, usageErrorf("unknown task type %q, use one of %s", t, strings.Join(taskTypes, ", "))
+		}
+		q.Types = append(q.Types, meilisearch.TaskType(t))
+	}
+
+	dates := []struct {
+		flag  string
+		value string
+		dst   *time.Time
+	}{
+		{"before-enqueued-at", f.beforeEnqueuedAt, &q.BeforeEnqueuedAt},
+		{"after-enqueued-at", f.afterEnqueuedAt, &q.AfterEnqueuedAt},
+		{"before-started-at", f.beforeStartedAt, &q.BeforeStartedAt},
+		{"after-started-at", f.afterStartedAt, &q.AfterStartedAt},
+		{"before-finished-at", f.beforeFinishedAt, &q.BeforeFinishedAt},
+		{"after-finished-at", f.afterFinishedAt, &q.AfterFinishedAt},
+	}
+
+	for _, d := range dates {
+		if len(d.value) == 0 {
+			continue
+		}
+
+		t, err := parseDate(d.value)
+		if err != nil {
+			return nil, usageErrorf("invalid --%s %q, use RFC 3339 or 2006-01-02", d.flag, d.value)
+		}
+		*d.dst = t
+	}
+
+	return q, nil
+}
+
+// hasFilters reports whether q filters on anything else than task uids.
+func hasFilters(q *meilisearch.TasksQuery) bool {
+	return len(q.IndexUIDS) != 0 || len(q.Statuses) != 0 || len(q.Types) != 0 || len(q.CanceledBy) != 0 ||
+		!q.BeforeEnqueuedAt.IsZero() || !q.AfterEnqueuedAt.IsZero() || !q.BeforeStartedAt.IsZero() ||
+		!q.AfterStartedAt.IsZero() || !q.BeforeFinishedAt.IsZero() || !q.AfterFinishedAt.IsZero()
+}
+
+// countTasks returns the number of tasks matching q whose status is one of
+// statuses, the only ones Meilisearch cancels or deletes.
+func (s *Session) countTasks(q meilisearch.TasksQuery, statuses ...meilisearch.TaskStatus) (int64, error) {
+	matching := make([]meilisearch.TaskStatus, 0, len(statuses))
+	for _, s := range statuses {
+		if len(q.Statuses) == 0 || slices.Contains(q.Statuses, s) {
+			matching = append(matching, s)
+		}
+	}
+
+	if len(matching) == 0 {
+		return 0, nil
+	}
+
+	q.Statuses = matching
+	q.Limit = 1
+	q.From = 0
+
+	res, err := s.Client().GetTasks(&q)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.Total, nil
+}
+
+// confirmTasks previews the number of tasks an action applies to and asks for
+// confirmation unless yes is set.
+func confirmTasks(action string, count int64, yes bool) (bool, error) {
+	if count == 0 {
+		color.New(color.FgCyan).Fprintln(os.Stderr, "no task matches the filters")
+		return false, nil
+	}
+
+	// the preview goes to stderr, stdout is kept for the result
+	color.New(color.FgYellow).Fprintf(os.Stderr, "%d tasks match the filters\n", count)
+	if yes {
+		return true, nil
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, usageErrorf("%s %d tasks requires confirmation, use --yes", strings.ToLower(action), count)
+	}
+
+	if !confirm(fmt.Sprintf("%s %d tasks?", action, count)) {
+		color.New(color.FgYellow).Fprintf(os.Stderr, "%s canceled\n", strings.ToLower(action))
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// nextTasksPage points q to the page after res and reports whether there is one.
+// next is 0 both on the last page and when the next task is the task 0, which
+// the client can not ask with from=0, so it is asked by its uid instead.
+func nextTasksPage(q *meilisearch.TasksQuery, res *meilisearch.TaskResult, listed int64) bool {
+	switch {
+	case res.Next != 0:
+		q.From = res.Next
+		return true
+	case listed >= res.Total || len(res.Results) == 0:
+		return false
+	case len(q.UIDS) != 0 && !slices.Contains(q.UIDS, 0):
+		return false
+	}
+
+	q.From = 0
+	q.UIDS = []int64{0}
+	return true
+}
+
+// parseDate parses RFC 3339 dates with or without time.
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.DateOnly, s)
+}
+
+func taskListCmd(sess *Session) *cobra.Command {
+	filter := &taskFilter{finished: true}
+	limit, from := int64(0), int64(0)
+	all := false
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "list tasks",
+		Long: `task list --statuses failed --index-uids movies
+task list --types documentAdditionOrUpdate --after-enqueued-at 2024-01-01 --all`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q, err := filter.tasksQuery()
+			if err != nil {
+				return err
+			}
+			q.Limit = limit
+			q.From = from
+			if all && limit == 0 {
+				q.Limit = allTasksPageSize
+			}
+
+			res, err := sess.Client().GetTasks(q)
+			if err != nil {
+				return err
+			}
+
+			tasks := res.Results
+			for all && nextTasksPage(q, res, int64(len(tasks))) {
+				res, err = sess.Client().GetTasks(q)
+				if err != nil {
+					return err
+				}
+				tasks = append(tasks, res.Results...)
+			}
+
+			sort.Slice(tasks, func(i, j int) bool {
+				return tasks[i].UID > tasks[j].UID
+			})
+
+			sess.render(tasks, func() {
+				for _, t := range tasks {
+					plainTask(&t)
+					lineBreaker()
+				}
+
+				fmt.Printf("Tasks: %d of %d\n", len(tasks), res.Total)
+				if res.Next != 0 {
+					color.Cyan("more tasks are available, use --from %d or --all", res.Next)
+				}
+			}, taskColumns...)
+			return nil
+		},
+	}
+
+	filter.addFlags(sess, list)
+	filter.addUIDsFlag(list.Flags())
+	list.Flags().Int64Var(&limit, "limit", 0, "set number of tasks per page, 20 by default")
+	list.Flags().Int64Var(&from, "from", 0, "set uid of the first task to list")
+	list.Flags().BoolVar(&all, "all", false, "follow the next cursor to list every matching task")
+
+	return list
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskFilter_TasksQuery(t *testing.T) {
+	f := &taskFilter{
+		indexUIDs:        []string{"movies"},
+		statuses:         []string{"failed", "canceled"},
+		types:            []string{"settingsUpdate"},
+		afterEnqueuedAt:  "2024-01-31",
+		beforeFinishedAt: "2024-02-01T10:00:00+02:00",
+	}
+
+	q, err := f.tasksQuery()
+	require.NoError(t, err)
+	require.Equal(t, []string{"movies"}, q.IndexUIDS)
+	require.Equal(t, []meilisearch.TaskStatus{meilisearch.TaskStatusFailed, meilisearch.TaskStatusCanceled}, q.Statuses)
+	require.Equal(t, []meilisearch.TaskType{meilisearch.TaskTypeSettingsUpdate}, q.Types)
+	require.True(t, q.AfterEnqueuedAt.Equal(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)))
+	require.True(t, q.BeforeFinishedAt.Equal(time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC)))
+	require.True(t, q.BeforeEnqueuedAt.IsZero())
+}
+
+func TestTaskFilter_Invalid(t *testing.T) {
+	_, err := (&taskFilter{statuses: []string{"done"}}).tasksQuery()
+	require.Equal(t, exitUsage, exitCode(err))
+
+	_, err = (&taskFilter{types: []string{"documentAddition"}}).tasksQuery()
+	require.Equal(t, exitUsage, exitCode(err))
+
+	_, err = (&taskFilter{afterStartedAt: "yesterday"}).tasksQuery()
+	require.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestHasFilters(t *testing.T) {
+	require.False(t, hasFilters(&meilisearch.TasksQuery{UIDS: []int64{1, 2}}))
+	require.False(t, hasFilters(&meilisearch.TasksQuery{IndexUIDS: []string{}}))
+	require.True(t, hasFilters(&meilisearch.TasksQuery{Statuses: []meilisearch.TaskStatus{meilisearch.TaskStatusSucceeded}}))
+	require.True(t, hasFilters(&meilisearch.TasksQuery{BeforeFinishedAt: time.Now()}))
+}
+
+func TestNextTasksPage(t *testing.T) {
+	q := &meilisearch.TasksQuery{Limit: 2}
+	require.True(t, nextTasksPage(q, &meilisearch.TaskResult{Results: make([]meilisearch.Task, 2), Next: 3, Total: 5}, 2))
+	require.Equal(t, int64(3), q.From)
+
+	// the next task is the task 0
+	require.True(t, nextTasksPage(q, &meilisearch.TaskResult{Results: make([]meilisearch.Task, 2), Total: 5}, 4))
+	require.Equal(t, []int64{0}, q.UIDS)
+
+	require.False(t, nextTasksPage(q, &meilisearch.TaskResult{Results: make([]meilisearch.Task, 1), Total: 1}, 5))
+
+	q = &meilisearch.TasksQuery{UIDS: []int64{4, 5}}
+	require.False(t, nextTasksPage(q, &meilisearch.TaskResult{Results: make([]meilisearch.Task, 1), Total: 2}, 1))
+}
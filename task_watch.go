@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/inancgumus/screen"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+)
+
+// watchStatuses are watched when task watch has no --statuses filter.
+var watchStatuses = []string{
+	string(meilisearch.TaskStatusEnqueued),
+	string(meilisearch.TaskStatusProcessing),
+	string(meilisearch.TaskStatusFailed),
+}
+
+func taskWatchCmd(sess *Session) *cobra.Command {
+	filter := &taskFilter{finished: true}
+	interval := time.Duration(0)
+	limit := int64(0)
+
+	watch := &cobra.Command{
+		Use:   "watch",
+		Short: "show a live table of enqueued, processing and failed tasks",
+		Long: `task watch --interval 1s --index-uids movies
+task watch --statuses processing --types documentAdditionOrUpdate
+
+Press Ctrl-C to stop watching.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return usageErrorf("interval must be greater than zero")
+			}
+
+			if len(filter.statuses) == 0 {
+				filter.statuses = watchStatuses
+			}
+
+			q, err := filter.tasksQuery()
+			if err != nil {
+				return err
+			}
+			q.Limit = limit
+
+			ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer cancel()
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				res, err := sess.Client().GetTasks(q)
+				if err != nil {
+					return err
+				}
+
+				screen.Clear()
+				screen.MoveTopLeft()
+				fmt.Printf("Every %s: task watch, %s, press Ctrl-C to stop\n\n", interval, time.Now().Format(time.TimeOnly))
+				drawTasks(os.Stdout, res, time.Now())
+
+				select {
+				case <-ctx.Done():
+					fmt.Println()
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	filter.addFlags(sess, watch)
+	watch.Flags().DurationVar(&interval, "interval", 2*time.Second, "set refresh interval")
+	watch.Flags().Int64Var(&limit, "limit", 20, "set maximum number of tasks shown")
+
+	return watch
+}
+
+// drawTasks writes the task table of task watch followed by a status summary.
+func drawTasks(out io.Writer, res *meilisearch.TaskResult, now time.Time) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	counts := make(map[meilisearch.TaskStatus]int)
+	writeTableRow(w, []string{"UID", "STATUS", "INDEX", "TYPE", "ELAPSED", "ERROR"})
+	for _, t := range res.Results {
+		counts[t.Status]++
+		writeTableRow(w, []string{
+			strconv.FormatInt(t.UID, 10),
+			string(t.Status),
+			tableCell(t.IndexUID),
+			string(t.Type),
+			taskElapsed(&t, now).Round(time.Second).String(),
+			tableCell(t.Error.Message),
+		})
+	}
+	_ = w.Flush()
+
+	fmt.Fprintf(out, "\nShown: %d of %d", len(res.Results), res.Total)
+	for _, s := range taskStatuses {
+		if n := counts[meilisearch.TaskStatus(s)]; n != 0 {
+			fmt.Fprintf(out, ", %s: %d", s, n)
+		}
+	}
+	fmt.Fprintln(out)
+}
+
+// taskElapsed is the waiting time of enqueued tasks, the running time of
+// processing tasks and the duration of finished tasks.
+func taskElapsed(t *meilisearch.Task, now time.Time) time.Duration {
+	switch {
+	case t.Status == meilisearch.TaskStatusEnqueued:
+		return now.Sub(t.EnqueuedAt)
+	case t.StartedAt.IsZero():
+		return 0
+	case t.FinishedAt.IsZero():
+		return now.Sub(t.StartedAt)
+	}
+
+	return t.FinishedAt.Sub(t.StartedAt)
+}
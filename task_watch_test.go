@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskElapsed(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	enqueued := &meilisearch.Task{Status: meilisearch.TaskStatusEnqueued, EnqueuedAt: now.Add(-time.Minute)}
+	require.Equal(t, time.Minute, taskElapsed(enqueued, now))
+
+	processing := &meilisearch.Task{Status: meilisearch.TaskStatusProcessing, StartedAt: now.Add(-5 * time.Second)}
+	require.Equal(t, 5*time.Second, taskElapsed(processing, now))
+
+	failed := &meilisearch.Task{
+		Status:     meilisearch.TaskStatusFailed,
+		StartedAt:  now.Add(-time.Hour),
+		FinishedAt: now.Add(-time.Hour + 3*time.Second),
+	}
+	require.Equal(t, 3*time.Second, taskElapsed(failed, now))
+}
+
+func TestDrawTasks(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	failed := meilisearch.Task{
+		UID:        2,
+		IndexUID:   "movies",
+		Status:     meilisearch.TaskStatusFailed,
+		Type:       meilisearch.TaskTypeSettingsUpdate,
+		StartedAt:  now.Add(-time.Minute),
+		FinishedAt: now.Add(-time.Minute + 2*time.Second),
+	}
+	failed.Error.Message = "invalid filterable attribute"
+
+	res := &meilisearch.TaskResult{
+		Results: []meilisearch.Task{
+			{UID: 3, Status: meilisearch.TaskStatusEnqueued, Type: meilisearch.TaskTypeDumpCreation, EnqueuedAt: now.Add(-10 * time.Second)},
+			failed,
+		},
+		Total: 5,
+	}
+
+	buf := new(bytes.Buffer)
+	drawTasks(buf, res, now)
+
+	require.Equal(t, `UID  STATUS    INDEX   TYPE            ELAPSED  ERROR
+3    enqueued          dumpCreation    10s      
+2    failed    movies  settingsUpdate  2s       invalid filterable attribute
+
+Shown: 2 of 5, enqueued: 1, failed: 1
+`, buf.String())
+}
@@ -1,6 +1,10 @@
 package util
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 func FormatBytesToHumanReadable(bytes uint64) string {
 	const (
@@ -30,3 +34,34 @@ func FormatBytesToHumanReadable(bytes uint64) string {
 
 	return fmt.Sprintf("%.2f %s", value, unit)
 }
+
+func ParseHumanReadableBytes(s string) (uint64, error) {
+	units := []struct {
+		suffix string
+		size   uint64
+	}{
+		{"TB", 1 << 40},
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+
+	s = strings.ToUpper(strings.TrimSpace(s))
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), 64)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("invalid size %q", s)
+			}
+			return uint64(v * float64(u.size)), nil
+		}
+	}
+
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return v, nil
+}
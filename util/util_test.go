@@ -0,0 +1,32 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatBytesToHumanReadable(t *testing.T) {
+	require.Equal(t, "512.00 Bytes", FormatBytesToHumanReadable(512))
+	require.Equal(t, "1.50 KB", FormatBytesToHumanReadable(1536))
+	require.Equal(t, "2.00 GB", FormatBytesToHumanReadable(2<<30))
+}
+
+func TestParseHumanReadableBytes(t *testing.T) {
+	for in, expected := range map[string]uint64{
+		"1024":  1024,
+		"10B":   10,
+		"1KB":   1 << 10,
+		"1.5mb": 3 << 19,
+		"2 GB":  2 << 30,
+	} {
+		v, err := ParseHumanReadableBytes(in)
+		require.NoError(t, err)
+		require.Equal(t, expected, v, in)
+	}
+}
+
+func TestParseHumanReadableBytes_Invalid(t *testing.T) {
+	_, err := ParseHumanReadableBytes("ten MB")
+	require.Error(t, err)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+const (
+	defaultWaitTimeout = time.Minute
+	waitInterval       = 100 * time.Millisecond
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+func addWaitFlags(flags *pflag.FlagSet, wait *bool, timeout *time.Duration) {
+	flags.BoolVar(wait, "wait", false, "wait for the task to finish and print it")
+	flags.DurationVar(timeout, "wait-timeout", defaultWaitTimeout, "maximum time to wait for the task")
+}
+
+// finishTask prints the enqueued task, or waits for it and prints the finished
+// task when wait is set. A failed task is returned as an error. The completions
+// of kinds are invalidated until the task is finished.
+func (s *Session) finishTask(res *meilisearch.TaskInfo, wait bool, timeout time.Duration, kinds ...string) error {
+	if !wait {
+		s.completions.invalidateTask(res.TaskUID, kinds...)
+		s.printTaskInfo(res)
+		return nil
+	}
+
+	t, err := s.waitForTask(res.TaskUID, timeout)
+	if err != nil {
+		s.completions.invalidateTask(res.TaskUID, kinds...)
+		return err
+	}
+
+	if len(kinds) != 0 {
+		s.completions.invalidate(kinds...)
+	}
+
+	s.printTask(t)
+	return taskError(t)
+}
+
+// waitForTask polls the task until it is finished, showing a spinner on a terminal.
+func (s *Session) waitForTask(uid int64, timeout time.Duration) (*meilisearch.Task, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stop := startSpinner(fmt.Sprintf("waiting for task %d", uid))
+	t, err := s.Client().WaitForTask(uid, meilisearch.WaitParams{
+		Context:  ctx,
+		Interval: waitInterval,
+	})
+	stop()
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("task %d is not finished after %s, check it with 'task get %d'", uid, timeout, uid)
+	}
+
+	return t, err
+}
+
+// taskFinished reports whether the task uid is finished, or can not be
+// checked anymore.
+func (s *Session) taskFinished(uid int64) bool {
+	client := s.Client()
+	if client == nil {
+		return true
+	}
+
+	t, err := client.GetTask(uid)
+	if err != nil {
+		return true
+	}
+
+	return t.Status != meilisearch.TaskStatusEnqueued && t.Status != meilisearch.TaskStatusProcessing
+}
+
+func taskError(t *meilisearch.Task) error {
+	if t.Status == meilisearch.TaskStatusFailed || t.Status == meilisearch.TaskStatusCanceled {
+		return newTaskError(t)
+	}
+
+	return nil
+}
+
+// startSpinner draws a spinner with msg on stderr until the returned func is called.
+func startSpinner(msg string) func() {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	started := time.Now()
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		for i := 0; ; i++ {
+			fmt.Fprintf(os.Stderr, "\r%s %s %s ", spinnerFrames[i%len(spinnerFrames)], msg,
+				time.Since(started).Round(time.Second))
+
+			select {
+			case <-done:
+				fmt.Fprint(os.Stderr, "\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+func taskWaitCmd(sess *Session) *cobra.Command {
+	timeout := time.Duration(0)
+
+	wait := &cobra.Command{
+		Use:               "wait",
+		Short:             "wait for a or many tasks to finish",
+		ValidArgsFunction: sess.completeTaskUIDs,
+		Long:              "task wait 1 2 3 4 --wait-timeout 5m",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("task uid is require 'task wait {task_uid or many 1 2 3 4}'")
+			}
+
+			uids, err := parseTaskUIDs(args)
+			if err != nil {
+				return err
+			}
+
+			var failed error
+			for i, uid := range uids {
+				t, err := sess.waitForTask(uid, timeout)
+				if err != nil {
+					return err
+				}
+
+				if i != 0 {
+					lineBreaker()
+				}
+				sess.printTask(t)
+
+				if err := taskError(t); err != nil && failed == nil {
+					failed = err
+				}
+			}
+
+			return failed
+		},
+	}
+
+	wait.Flags().DurationVar(&timeout, "wait-timeout", defaultWaitTimeout, "maximum time to wait for each task")
+
+	return wait
+}
+
+func parseTaskUIDs(args []string) ([]int64, error) {
+	uids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		uid, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return nil, usageErrorf("invalid task uid %q", arg)
+		}
+		uids = append(uids, uid)
+	}
+
+	return uids, nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskUIDs(t *testing.T) {
+	uids, err := parseTaskUIDs([]string{"1", "20"})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 20}, uids)
+
+	_, err = parseTaskUIDs([]string{"1", "x"})
+	require.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestTaskError(t *testing.T) {
+	require.NoError(t, taskError(&meilisearch.Task{Status: meilisearch.TaskStatusSucceeded}))
+
+	failed := &meilisearch.Task{UID: 7, Status: meilisearch.TaskStatusFailed}
+	failed.Error.Code = "invalid_settings_filterable_attributes"
+	failed.Error.Type = "invalid_request"
+	failed.Error.Message = "bad attribute"
+
+	err := taskError(failed)
+	require.EqualError(t, err, "task 7 failed: bad attribute (invalid_settings_filterable_attributes)")
+	require.Equal(t, exitValidation, exitCode(err))
+
+	canceled := &meilisearch.Task{UID: 8, Status: meilisearch.TaskStatusCanceled, CanceledBy: 9}
+	require.EqualError(t, taskError(canceled), "task 8 was canceled by task 9")
+}